@@ -0,0 +1,159 @@
+package gsv
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"strings"
+)
+
+// ColorMode selects the colours a StdoutVisualizer may use
+type ColorMode int
+
+const (
+	// ColorAuto detects the colour support of the terminal when writing
+	// to os.Stdout and uses no colour for other writers
+	ColorAuto ColorMode = iota
+	// ColorNone draws monochrome frames with '#'
+	ColorNone
+	// Color16 uses the 16 basic ANSI colours
+	Color16
+	// Color256 uses the xterm 256-colour palette
+	Color256
+	// ColorTrue uses 24-bit colours
+	ColorTrue
+)
+
+// ColorModes maps names, e.g. of command line flags, to colour modes
+var ColorModes = map[string]ColorMode{
+	"auto":      ColorAuto,
+	"none":      ColorNone,
+	"16":        Color16,
+	"256":       Color256,
+	"truecolor": ColorTrue,
+}
+
+// DetectColor returns the colour support of the terminal as announced by
+// the NO_COLOR, COLORTERM and TERM environment variables
+func DetectColor() ColorMode {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return ColorNone
+	}
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case term == "" || term == "dumb":
+		return ColorNone
+	case strings.Contains(term, "256color"):
+		return Color256
+	}
+	return Color16
+}
+
+// Theme maps a bar height between 0 and 1 to its colour
+type Theme func(t float64) color.RGBA
+
+// Themes maps names, e.g. of command line flags, to themes
+var Themes = map[string]Theme{
+	"rainbow": Rainbow,
+	"viridis": Viridis,
+}
+
+// Rainbow runs through the hues from red over green to violet
+func Rainbow(t float64) color.RGBA {
+	h := clamp01(t) * 5
+	x := 1 - math.Abs(math.Mod(h, 2)-1)
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g = 1, x
+	case 1:
+		r, g = x, 1
+	case 2:
+		g, b = 1, x
+	case 3:
+		g, b = x, 1
+	default:
+		r, b = x, 1
+	}
+	return color.RGBA{uint8(r * 255), uint8(g * 255), uint8(b * 255), 255}
+}
+
+// viridisStops samples the viridis colour map in equal steps
+var viridisStops = []color.RGBA{
+	{0x44, 0x01, 0x54, 255},
+	{0x48, 0x28, 0x78, 255},
+	{0x3e, 0x49, 0x89, 255},
+	{0x31, 0x68, 0x8e, 255},
+	{0x26, 0x82, 0x8e, 255},
+	{0x1f, 0x9e, 0x89, 255},
+	{0x35, 0xb7, 0x79, 255},
+	{0x6e, 0xce, 0x58, 255},
+	{0xb5, 0xde, 0x2b, 255},
+	{0xfd, 0xe7, 0x25, 255},
+}
+
+// Viridis is the perceptually uniform colour map from dark violet to yellow
+func Viridis(t float64) color.RGBA {
+	pos := clamp01(t) * float64(len(viridisStops)-1)
+	k := int(pos)
+	if k == len(viridisStops)-1 {
+		return viridisStops[k]
+	}
+	a, b, f := viridisStops[k], viridisStops[k+1], pos-float64(k)
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*f + 0.5)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
+
+func clamp01(t float64) float64 {
+	return math.Max(0, math.Min(1, t))
+}
+
+// ansi16 holds the RGB values of the 16 basic ANSI colours as xterm shows
+// them; index k has the foreground code 30+k or 90+k-8
+var ansi16 = [16]color.RGBA{
+	{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+	{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+	{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+// writeColor writes the escape sequence that sets the foreground to c
+func writeColor(buf *bytes.Buffer, mode ColorMode, c color.RGBA) {
+	switch mode {
+	case ColorTrue:
+		fmt.Fprintf(buf, "\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+	case Color256:
+		cube := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+		fmt.Fprintf(buf, "\033[38;5;%dm", 16+36*cube(c.R)+6*cube(c.G)+cube(c.B))
+	case Color16:
+		k := nearest16(c)
+		if k < 8 {
+			fmt.Fprintf(buf, "\033[%dm", 30+k)
+		} else {
+			fmt.Fprintf(buf, "\033[%dm", 90+k-8)
+		}
+	}
+}
+
+// nearest16 returns the index of the basic ANSI colour closest to c
+func nearest16(c color.RGBA) int {
+	best, dist := 0, math.MaxInt
+	for k, a := range ansi16 {
+		dr, dg, db := int(c.R)-int(a.R), int(c.G)-int(a.G), int(c.B)-int(a.B)
+		if d := dr*dr + dg*dg + db*db; d < dist {
+			best, dist = k, d
+		}
+	}
+	return best
+}
+
+// colorReset restores the default colours
+const colorReset = "\033[0m"
@@ -0,0 +1,102 @@
+package gsv
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestDetectColor checks the colour mode chosen from the environment.
+func TestDetectColor(t *testing.T) {
+	for _, tc := range []struct {
+		noColor   bool
+		colorterm string
+		term      string
+		want      ColorMode
+	}{
+		{false, "truecolor", "xterm-256color", ColorTrue},
+		{false, "24bit", "", ColorTrue},
+		{false, "", "xterm-256color", Color256},
+		{false, "", "xterm", Color16},
+		{false, "", "dumb", ColorNone},
+		{false, "", "", ColorNone},
+		{true, "truecolor", "xterm-256color", ColorNone},
+	} {
+		t.Setenv("COLORTERM", tc.colorterm)
+		t.Setenv("TERM", tc.term)
+		t.Setenv("NO_COLOR", "")
+		if !tc.noColor {
+			os.Unsetenv("NO_COLOR")
+		}
+		if got := DetectColor(); got != tc.want {
+			t.Errorf("COLORTERM=%q TERM=%q NO_COLOR=%v: got %d, want %d", tc.colorterm, tc.term, tc.noColor, got, tc.want)
+		}
+	}
+}
+
+// TestWriteColor checks the escape codes of each colour mode.
+func TestWriteColor(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	for mode, want := range map[ColorMode]string{
+		ColorTrue: "\033[38;2;255;0;0m",
+		Color256:  "\033[38;5;196m",
+		Color16:   "\033[91m",
+		ColorNone: "",
+	} {
+		var buf bytes.Buffer
+		writeColor(&buf, mode, red)
+		if buf.String() != want {
+			t.Errorf("mode %d: got %q, want %q", mode, buf.String(), want)
+		}
+	}
+}
+
+// TestThemes checks that the themes span distinct colours and clamp values.
+func TestThemes(t *testing.T) {
+	for name, theme := range Themes {
+		if theme(0) == theme(1) {
+			t.Errorf("%s: lowest and highest bars have the same colour", name)
+		}
+		if theme(-1) != theme(0) || theme(2) != theme(1) {
+			t.Errorf("%s: values outside [0, 1] are not clamped", name)
+		}
+	}
+	if got := Viridis(1); got != viridisStops[len(viridisStops)-1] {
+		t.Errorf("Viridis(1) = %v", got)
+	}
+	if got := Rainbow(0); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Rainbow(0) = %v", got)
+	}
+}
+
+// TestStdoutColor checks that bars are coloured by value and highlighted.
+func TestStdoutColor(t *testing.T) {
+	var buf bytes.Buffer
+	sv := NewStdoutVisualizer(Config{Max: 3, Fps: 1000, Mode: 2})
+	sv.Clear = ClearNone
+	sv.Color = ColorTrue
+	sv.Theme = Viridis
+	sv.Out = &buf
+	sv.Setup("test")
+	sv.AddFrame([]int{1, 3})
+	if strings.Contains(buf.String(), "#") || !strings.Contains(buf.String(), "█") {
+		t.Errorf("colour frame not drawn with blocks: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "\033[38;2;68;1;84m█") || !strings.Contains(buf.String(), "\033[38;2;253;231;37m█") {
+		t.Errorf("bars not coloured by value: %q", buf.String())
+	}
+
+	buf.Reset()
+	sv.AddEvent(Event{Op: OpSwap, I: 0, J: 1})
+	sv.AddFrame([]int{3, 1})
+	swap := gifPalette[roleSwap+1].(color.RGBA)
+	if want := fmt.Sprintf("\033[38;2;%d;%d;%dm█", swap.R, swap.G, swap.B); !strings.Contains(buf.String(), want) {
+		t.Errorf("swapped bars not highlighted: %q", buf.String())
+	}
+	if !strings.HasSuffix(strings.Split(buf.String(), "\n")[0], colorReset) {
+		t.Errorf("row does not reset the colour: %q", buf.String())
+	}
+}
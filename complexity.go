@@ -0,0 +1,381 @@
+package gsv
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// Model is a candidate for how the cost of an algorithm grows with the
+// number of values n
+type Model struct {
+	Name string
+	F    func(n float64) float64
+}
+
+// Models are the growth functions Sweep results are fitted to
+var Models = []Model{
+	{"n", func(n float64) float64 { return n }},
+	{"n log n", func(n float64) float64 { return n * math.Log2(n) }},
+	{"n log^2 n", func(n float64) float64 { return n * math.Log2(n) * math.Log2(n) }},
+	{"n^1.5", func(n float64) float64 { return math.Pow(n, 1.5) }},
+	{"n^2", func(n float64) float64 { return n * n }},
+	{"n^3", func(n float64) float64 { return n * n * n }},
+}
+
+// Point is a cost measured for n values
+type Point struct {
+	N    int
+	Cost float64
+}
+
+// Fit is a Model scaled to match measured points
+type Fit struct {
+	Model string
+	// Scale is the constant factor c of the cost c·F(n)
+	Scale float64
+	// Residual is the root mean square of the relative errors of the
+	// scaled model, e.g. 0.05 for a typical deviation of 5%
+	Residual float64
+}
+
+// FitModels fits points to each of models and returns the fits from the
+// best to the worst. Relative errors are minimized so that the largest
+// sizes do not outweigh the others. Points without cost are ignored.
+func FitModels(points []Point, models []Model) []Fit {
+	fits := make([]Fit, 0, len(models))
+	for _, m := range models {
+		// minimize Σ(1 - c·r)² with r = F(n)/cost
+		var sum, squares float64
+		for _, p := range points {
+			if p.Cost > 0 {
+				r := m.F(float64(p.N)) / p.Cost
+				sum += r
+				squares += r * r
+			}
+		}
+		if squares == 0 {
+			continue
+		}
+		fit := Fit{Model: m.Name, Scale: sum / squares}
+		k := 0
+		for _, p := range points {
+			if p.Cost > 0 {
+				e := 1 - fit.Scale*m.F(float64(p.N))/p.Cost
+				fit.Residual += e * e
+				k++
+			}
+		}
+		fit.Residual = math.Sqrt(fit.Residual / float64(k))
+		fits = append(fits, fit)
+	}
+	sort.SliceStable(fits, func(i, j int) bool {
+		return fits[i].Residual < fits[j].Residual
+	})
+	return fits
+}
+
+// Exponent returns the slope of the cost over n on a log-log scale, e.g.
+// about 2 for quadratic and a little above 1 for n log n growth. It is 0
+// for fewer than two points with cost.
+func Exponent(points []Point) float64 {
+	var xs, ys []float64
+	for _, p := range points {
+		if p.Cost > 0 && p.N > 0 {
+			xs = append(xs, math.Log(float64(p.N)))
+			ys = append(ys, math.Log(p.Cost))
+		}
+	}
+	if len(xs) < 2 {
+		return 0
+	}
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(len(xs))
+	my /= float64(len(xs))
+	var cov, v float64
+	for i := range xs {
+		cov += (xs[i] - mx) * (ys[i] - my)
+		v += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if v == 0 {
+		return 0
+	}
+	return cov / v
+}
+
+// Sweep runs algorithms over growing input sizes to measure how their
+// cost grows
+type Sweep struct {
+	// Sizes are the numbers of values; 16 to 65536 in steps of 4 if nil
+	Sizes []int
+	// Input generates the values; gen.Random if nil
+	Input gen.Generator
+	// Max is the highest value; 0 uses the number of values
+	Max int
+	// Budget stops a run after this many operations, and the sweep of
+	// the algorithm with it; 0 allows 10⁸. It applies to the counted and
+	// to the timed runs.
+	Budget int
+	// Timeout stops a run after this wall time like Budget; 0 allows 10s.
+	// The timed runs of a size share one Timeout.
+	Timeout time.Duration
+	// Seed seeds the inputs, so every algorithm sorts the same values
+	Seed int64
+}
+
+// defaultSizes are the sizes of a Sweep without Sizes
+var defaultSizes = []int{16, 64, 256, 1024, 4096, 16384, 65536}
+
+// Sample is the cost of sorting N values
+type Sample struct {
+	N int
+	// Ops counts comparisons, swaps and writes to the array and to
+	// auxiliary memory
+	Ops  int
+	Time time.Duration
+}
+
+// Complexity is the result of a Sweep for one algorithm
+type Complexity struct {
+	Name    string
+	Samples []Sample
+	// Stopped is the size at which a run exceeded the budget of the
+	// sweep or the limits of the registered algorithm, or 0 if all sizes
+	// were run
+	Stopped int
+	// Ops and Time are the fits of the operations and of the run time
+	// from the best to the worst, or nil if fewer than 5 sizes were run,
+	// too few to tell the models apart
+	Ops, Time []Fit
+	// Exponent is the slope of the operations on a log-log scale
+	Exponent float64
+}
+
+// overBudget is raised by a budgetTracer to stop a run
+type overBudget struct{}
+
+// budgetTracer counts operations and stops the run once it exceeds a
+// number of operations or a deadline
+type budgetTracer struct {
+	Counter
+	ops      int
+	limit    int
+	deadline time.Time
+}
+
+func (bt *budgetTracer) count() {
+	bt.ops++
+	if bt.ops > bt.limit || (bt.ops%1024 == 0 && time.Now().After(bt.deadline)) {
+		panic(overBudget{})
+	}
+}
+
+// deadlineTracer stops a timed run like budgetTracer but does not count
+// the kinds of operations, so the run is timed almost as if untraced
+type deadlineTracer struct {
+	nopTracer
+	ops      int
+	limit    int
+	deadline time.Time
+}
+
+func (dt *deadlineTracer) count() {
+	dt.ops++
+	if dt.ops > dt.limit || (dt.ops%1024 == 0 && time.Now().After(dt.deadline)) {
+		panic(overBudget{})
+	}
+}
+
+func (dt *deadlineTracer) Compare(i, j int)  { dt.count() }
+func (dt *deadlineTracer) Swap(i, j int)     { dt.count() }
+func (dt *deadlineTracer) Write(i, v int)    { dt.count() }
+func (dt *deadlineTracer) AuxWrite(i, v int) { dt.count() }
+
+func (bt *budgetTracer) Compare(i, j int) {
+	bt.Counter.Compare(i, j)
+	bt.count()
+}
+
+func (bt *budgetTracer) Swap(i, j int) {
+	bt.Counter.Swap(i, j)
+	bt.count()
+}
+
+func (bt *budgetTracer) Write(i, v int) {
+	bt.Counter.Write(i, v)
+	bt.count()
+}
+
+func (bt *budgetTracer) AuxWrite(i, v int) {
+	bt.Counter.AuxWrite(i, v)
+	bt.count()
+}
+
+// Run sorts the inputs of the sweep with s, from the smallest to the
+// largest, and fits the costs to Models. The operations are counted in
+// one run; the time is taken from runs repeated for at least 10ms. The
+// sweep stops at the first size that exceeds the budget or, if name is
+// registered, that the algorithm cannot sort; see Algorithm.Check.
+func (sw Sweep) Run(name string, s TraceSorter) Complexity {
+	sizes, input := sw.Sizes, sw.Input
+	if sizes == nil {
+		sizes = defaultSizes
+	}
+	if input == nil {
+		input = gen.Random
+	}
+	a, registered := Lookup(name)
+	c := Complexity{Name: name}
+	for _, n := range sizes {
+		max := sw.Max
+		if max <= 0 {
+			max = n
+		}
+		arr := input(n, max, rand.New(rand.NewSource(sw.Seed)))
+		if registered && a.Check(arr) != nil {
+			c.Stopped = n
+			break
+		}
+		ops, ok := sw.count(arr, s)
+		if !ok {
+			c.Stopped = n
+			break
+		}
+		d, ok := sw.time(arr, s)
+		if !ok {
+			c.Stopped = n
+			break
+		}
+		c.Samples = append(c.Samples, Sample{N: n, Ops: ops, Time: d})
+	}
+
+	var ops, times []Point
+	for _, sm := range c.Samples {
+		ops = append(ops, Point{sm.N, float64(sm.Ops)})
+		times = append(times, Point{sm.N, float64(sm.Time)})
+	}
+	c.Exponent = Exponent(ops)
+	if len(c.Samples) >= minSamples {
+		c.Ops = FitModels(ops, Models)
+		c.Time = FitModels(times, Models)
+	}
+	return c
+}
+
+// limits returns the operations a run may take and the time it must end
+// by if it starts now
+func (sw Sweep) limits() (limit int, deadline time.Time) {
+	limit, timeout := sw.Budget, sw.Timeout
+	if limit <= 0 {
+		limit = 1e8
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return limit, time.Now().Add(timeout)
+}
+
+// bounded calls run and reports false if it stopped with overBudget
+func bounded(run func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, over := r.(overBudget); !over {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+	run()
+	return true
+}
+
+// count returns the operations of sorting a copy of arr with s, or false
+// if the run exceeds the budget
+func (sw Sweep) count(arr []int, s TraceSorter) (int, bool) {
+	bt := &budgetTracer{}
+	bt.limit, bt.deadline = sw.limits()
+	ok := bounded(func() { s(append([]int(nil), arr...), bt) })
+	return bt.ops, ok
+}
+
+// time returns the average time of sorting copies of arr with s, or false
+// if a run exceeds the budget. Randomized sorters may take much longer in
+// a timed run than in the counted one.
+func (sw Sweep) time(arr []int, s TraceSorter) (time.Duration, bool) {
+	dt := &deadlineTracer{}
+	dt.limit, dt.deadline = sw.limits()
+	work := make([]int, len(arr))
+	var total time.Duration
+	runs := 0
+	for total < 10*time.Millisecond {
+		copy(work, arr)
+		dt.ops = 0
+		start := time.Now()
+		if !bounded(func() { s(work, dt) }) {
+			return 0, false
+		}
+		total += time.Since(start)
+		runs++
+	}
+	return total / time.Duration(runs), true
+}
+
+// WriteComplexity writes a table of the best fits of results to w. Fits
+// of fewer than three sizes are left out as any model matches them.
+func WriteComplexity(w io.Writer, results []Complexity) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "algorithm\tsizes\tops\tresidual\tnext best\texponent\ttime\tresidual\t")
+	for _, c := range results {
+		sizes := "-"
+		switch len(c.Samples) {
+		case 0:
+		case 1:
+			sizes = fmt.Sprint(c.Samples[0].N)
+		default:
+			sizes = fmt.Sprintf("%d-%d", c.Samples[0].N, c.Samples[len(c.Samples)-1].N)
+		}
+		if c.Stopped > 0 {
+			sizes += fmt.Sprintf(" (stopped at %d)", c.Stopped)
+		}
+		if len(c.Samples) < minSamples {
+			fmt.Fprintf(tw, "%s\t%s\tinsufficient samples\t-\t-\t-\t-\t-\t\n", c.Name, sizes)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%.2f\t%s\t%s\t\n", c.Name, sizes,
+			fitName(c.Ops, 0), fitResidual(c.Ops, 0), fitName(c.Ops, 1), c.Exponent,
+			fitName(c.Time, 0), fitResidual(c.Time, 0))
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("gsv: %w", err)
+	}
+	return nil
+}
+
+// minSamples is the number of sizes needed to tell the models apart
+const minSamples = 5
+
+// fitName returns the model of fits[k] in O notation
+func fitName(fits []Fit, k int) string {
+	if k >= len(fits) {
+		return "-"
+	}
+	return "O(" + fits[k].Model + ")"
+}
+
+// fitResidual formats the residual of fits[k] as a percentage
+func fitResidual(fits []Fit, k int) string {
+	if k >= len(fits) {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*fits[k].Residual)
+}
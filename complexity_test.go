@@ -0,0 +1,105 @@
+package gsv
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestFitModels checks that exact costs are matched by their model.
+func TestFitModels(t *testing.T) {
+	for _, m := range Models {
+		var points []Point
+		for n := 8; n <= 4096; n *= 2 {
+			points = append(points, Point{n, 3 * m.F(float64(n))})
+		}
+		fits := FitModels(points, Models)
+		if len(fits) != len(Models) || fits[0].Model != m.Name {
+			t.Errorf("%s: expected the best fit, got %+v", m.Name, fits)
+			continue
+		}
+		if fits[0].Residual > 1e-9 || math.Abs(fits[0].Scale-3) > 1e-9 {
+			t.Errorf("%s: expected scale 3 without residual, got %+v", m.Name, fits[0])
+		}
+		if fits[1].Residual < 0.1 {
+			t.Errorf("%s: expected %s to fit clearly worse, got %+v", m.Name, fits[1].Model, fits[1])
+		}
+	}
+
+	points := []Point{{10, 100}, {100, 10000}, {1000, 1000000}}
+	if e := Exponent(points); math.Abs(e-2) > 1e-9 {
+		t.Errorf("Expected exponent 2, got %v", e)
+	}
+	if e := Exponent(points[:1]); e != 0 {
+		t.Errorf("Expected exponent 0 for one point, got %v", e)
+	}
+}
+
+// TestSweep checks the fitted growth of known algorithms and that the
+// budget stops slow ones before they are fitted.
+func TestSweep(t *testing.T) {
+	sw := Sweep{Sizes: []int{16, 32, 64, 128, 256}, Input: gen.Reversed, Seed: 1}
+	tests := map[string]struct {
+		sort  TraceSorter
+		model string
+	}{
+		"insertion": {InsertionSortTraced, "n^2"},
+		"merge":     {MergeSortTraced, "n log n"},
+		"counting":  {CountingSortTraced, "n"},
+	}
+	var results []Complexity
+	for name, test := range tests {
+		c := sw.Run(name, test.sort)
+		if len(c.Samples) != 5 || c.Stopped != 0 {
+			t.Errorf("%s: expected all sizes, got %+v", name, c)
+		} else if c.Ops[0].Model != test.model {
+			t.Errorf("%s: expected O(%s), got %+v", name, test.model, c.Ops)
+		}
+		results = append(results, c)
+	}
+
+	sw.Budget = 100000
+	c := sw.Run("stooge", StoogeSortTraced)
+	if c.Stopped != 128 || len(c.Samples) != 3 || c.Ops != nil {
+		t.Errorf("Expected the sweep to stop at 128 without a fit, got %+v", c)
+	}
+	results = append(results, c)
+
+	var buf bytes.Buffer
+	if err := WriteComplexity(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	for _, want := range []string{"O(n log n)", "O(n^2)", "16-64 (stopped at 128)  insufficient samples"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected the table to contain %q:\n%s", want, text)
+		}
+	}
+}
+
+// TestSweepLimits checks that a sweep stops at the sizes the registry
+// rules out and when a timed run exceeds the budget.
+func TestSweepLimits(t *testing.T) {
+	sw := Sweep{Sizes: []int{4, 8, 16}, Input: gen.Sorted, Seed: 1}
+	c := sw.Run("bogo", BogoSortTraced)
+	if c.Stopped != 16 || len(c.Samples) != 2 {
+		t.Errorf("Expected the sweep to stop at 16, got %+v", c)
+	}
+
+	// only the counted run is quick, like an unlucky randomized sorter
+	runs := 0
+	slow := func(arr []int, t Tracer) {
+		runs++
+		for runs > 1 {
+			t.Compare(0, 1)
+		}
+	}
+	sw.Budget = 1000
+	c = sw.Run("slow", slow)
+	if c.Stopped != 4 || len(c.Samples) != 0 {
+		t.Errorf("Expected the timed run to stop the sweep at 4, got %+v", c)
+	}
+}
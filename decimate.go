@@ -0,0 +1,163 @@
+package gsv
+
+import "time"
+
+// Every returns a Visualizer that passes only every nth frame on to v.
+// The first and the final frame are always kept. Operations are passed on
+// as they happen, so an EventVisualizer highlights everything that
+// changed since the previous kept frame.
+func Every(n int, v Visualizer) Visualizer {
+	if n < 1 {
+		n = 1
+	}
+	return &everyVisualizer{n: n, v: v}
+}
+
+type everyVisualizer struct {
+	n     int
+	v     Visualizer
+	count int
+	last  []int
+}
+
+func (ev *everyVisualizer) Setup(name string) error {
+	ev.count = 0
+	ev.last = ev.last[:0]
+	return ev.v.Setup(name)
+}
+
+func (ev *everyVisualizer) AddEvent(e Event) {
+	if v, ok := ev.v.(EventVisualizer); ok {
+		v.AddEvent(e)
+	}
+}
+
+func (ev *everyVisualizer) AddFrame(arr []int) error {
+	ev.count++
+	if (ev.count-1)%ev.n == 0 {
+		ev.last = ev.last[:0]
+		return ev.v.AddFrame(arr)
+	}
+	ev.last = append(ev.last[:0], arr...)
+	return nil
+}
+
+func (ev *everyVisualizer) Complete() error {
+	if len(ev.last) > 0 {
+		if err := ev.v.AddFrame(ev.last); err != nil {
+			ev.v.Complete()
+			return err
+		}
+	}
+	return ev.v.Complete()
+}
+
+// Stride returns the smallest n for which Every(n, v) keeps at most budget
+// of total frames
+func Stride(total, budget int) int {
+	if budget < 2 {
+		budget = 2
+	}
+	kept := func(n int) int {
+		k := (total-1)/n + 1
+		if (total-1)%n != 0 {
+			k++
+		}
+		return k
+	}
+	n := total / budget
+	if n < 1 {
+		n = 1
+	}
+	for total > 1 && kept(n) > budget {
+		n++
+	}
+	return n
+}
+
+// FramesFor returns the number of frames an animation of duration d
+// shows at fps frames per second, e.g. for use with Budget
+func FramesFor(d time.Duration, fps int) int {
+	return int(d * time.Duration(fps) / time.Second)
+}
+
+// Budget returns a Visualizer that fits a run of any length into at most
+// frames frames of v, spread evenly and always including the first and
+// the final frame. As the length of a run is not known in advance, frames
+// are buffered until Complete; the buffer holds at most twice the budget.
+// Use Every with Stride on a recorded Trace to keep highlights as well.
+func Budget(frames int, v Visualizer) Visualizer {
+	if frames < 2 {
+		frames = 2
+	}
+	return &budgetVisualizer{budget: frames, v: v}
+}
+
+type budgetVisualizer struct {
+	budget   int
+	v        Visualizer
+	name     string
+	stride   int
+	count    int
+	kept     [][]int
+	last     []int
+	lastKept bool
+}
+
+func (bv *budgetVisualizer) Setup(name string) error {
+	bv.name = name
+	bv.stride = 1
+	bv.count = 0
+	bv.kept = nil
+	bv.last = nil
+	bv.lastKept = false
+	return nil
+}
+
+func (bv *budgetVisualizer) AddFrame(arr []int) error {
+	frame := append([]int(nil), arr...)
+	bv.last = frame
+	bv.lastKept = bv.count%bv.stride == 0
+	if bv.lastKept {
+		bv.kept = append(bv.kept, frame)
+		if len(bv.kept) == 2*bv.budget {
+			for i := 0; i < bv.budget; i++ {
+				bv.kept[i] = bv.kept[2*i]
+			}
+			for i := bv.budget; i < len(bv.kept); i++ {
+				bv.kept[i] = nil
+			}
+			bv.kept = bv.kept[:bv.budget]
+			bv.stride *= 2
+			bv.lastKept = false
+		}
+	}
+	bv.count++
+	return nil
+}
+
+func (bv *budgetVisualizer) Complete() error {
+	frames := bv.kept
+	if bv.last != nil && !bv.lastKept {
+		frames = append(frames, bv.last)
+	}
+	if n := len(frames); n > bv.budget {
+		selected := make([][]int, bv.budget)
+		for i := range selected {
+			selected[i] = frames[i*(n-1)/(bv.budget-1)]
+		}
+		frames = selected
+	}
+
+	err := bv.v.Setup(bv.name)
+	for _, frame := range frames {
+		if err != nil {
+			break
+		}
+		err = bv.v.AddFrame(frame)
+	}
+	if cerr := bv.v.Complete(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package gsv
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// collectFrames returns a Visualizer that appends copies of all frames to
+// frames
+func collectFrames(frames *[][]int) Visualizer {
+	return FrameGen(func(arr []int) {
+		*frames = append(*frames, cloneArray(arr))
+	})
+}
+
+// TestEvery checks that every nth frame is kept, plus the final one.
+func TestEvery(t *testing.T) {
+	var frames [][]int
+	v := Every(3, collectFrames(&frames))
+	v.Setup("every")
+	for i := 0; i < 8; i++ {
+		v.AddFrame([]int{i})
+	}
+	v.Complete()
+
+	expected := [][]int{{0}, {3}, {6}, {7}}
+	if !reflect.DeepEqual(frames, expected) {
+		t.Errorf("Expected %v, got %v", expected, frames)
+	}
+}
+
+// TestBudget checks that runs of any length fit the frame budget and
+// keep their first and final frame.
+func TestBudget(t *testing.T) {
+	for _, total := range []int{1, 5, 10, 11, 99, 1000, 1234} {
+		var frames [][]int
+		v := Budget(10, collectFrames(&frames))
+		v.Setup("budget")
+		for i := 0; i < total; i++ {
+			v.AddFrame([]int{i})
+		}
+		v.Complete()
+
+		if len(frames) > 10 || len(frames) < 1 {
+			t.Errorf("%d frames: expected at most 10 frames, got %d", total, len(frames))
+			continue
+		}
+		if frames[0][0] != 0 || frames[len(frames)-1][0] != total-1 {
+			t.Errorf("%d frames: expected first and final frame, got %v", total, frames)
+		}
+		if total >= 10 && len(frames) != 10 {
+			t.Errorf("%d frames: expected the budget to be used, got %d", total, len(frames))
+		}
+	}
+}
+
+// TestStride checks that a stride keeps a recorded run within the budget.
+func TestStride(t *testing.T) {
+	tr := Record("bubble", gen.Random(30, 9, nil), BubbleSortTraced)
+	budget := FramesFor(2*time.Second, 10)
+
+	var frames [][]int
+	tr.Replay(Every(Stride(tr.Frames(), budget), collectFrames(&frames)))
+	if len(frames) > budget {
+		t.Errorf("Expected at most %d frames, got %d", budget, len(frames))
+	}
+	if !reflect.DeepEqual(frames[len(frames)-1], tr.Result()) {
+		t.Error("Expected the sorted array as final frame")
+	}
+	for total := 1; total < 200; total++ {
+		for budget := 2; budget < 20; budget++ {
+			n := Stride(total, budget)
+			kept := 0
+			for k := 0; k < total; k++ {
+				if k%n == 0 || k == total-1 {
+					kept++
+				}
+			}
+			if kept > budget {
+				t.Fatalf("Stride(%d, %d) = %d keeps %d frames", total, budget, n, kept)
+			}
+		}
+	}
+}
@@ -1,104 +1,562 @@
 package main
 
 import (
-	cryptoRand "crypto/rand"
 	"flag"
 	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
 	gsv "simonwaldherr.de/go/GolangSortingVisualization"
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func randomArray(n int, max int) []int {
-	arr := make([]int, n)
-	for i := 0; i < n; i++ {
-		b := make([]byte, 1)
-		cryptoRand.Read(b)
-		number := float64(b[0])
-		arr[i] = int(number / 255 * float64(max))
+// runTUI starts the interactive player. Count and Max fit the terminal
+// unless they are given on the command line. Values read with -from are
+// offered as an extra input. Randomized algorithms are left out as they
+// rarely finish on that many values.
+func runTUI(opts options) {
+	cfg := opts.cfg
+	if !flagSet("count") {
+		cfg.Count = 0
 	}
-	return arr
+	if !flagSet("max") {
+		cfg.Max = 0
+	}
+	algorithms := map[string]gsv.TraceSorter{}
+	for _, a := range gsv.Algorithms() {
+		if a.Rand == nil {
+			algorithms[a.Name] = a.Trace
+		}
+	}
+	inputs := map[string]gen.Generator{}
+	for name := range gen.Generators {
+		inputs[name] = opts.generator(name)
+	}
+	if opts.values != nil {
+		inputs[opts.input] = func(n, max int, rng *rand.Rand) []int {
+			return append([]int(nil), opts.values...)
+		}
+	}
+	ui := &gsv.TUI{
+		Algorithms: algorithms,
+		Inputs:     inputs,
+		Config:     cfg,
+		Color:      gsv.ColorModes[opts.color],
+		Theme:      gsv.Themes[opts.theme],
+	}
+	if err := ui.Run(os.Stdin, os.Stdout); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// runRace sorts the same input with the comma separated algorithms in
+// names and shows them side by side
+func runRace(opts options, names, align string) {
+	if opts.origin {
+		fmt.Println("-origin cannot be used with -race")
+		return
+	}
+	input, rng, ok := makeInput(&opts)
+	if !ok {
+		return
+	}
+	algorithms, ok := selectAlgorithms(names, nil)
+	if !ok {
+		return
+	}
+	var racers []gsv.Racer
+	for _, a := range algorithms {
+		if err := a.Check(input); err != nil {
+			fmt.Println(err)
+			return
+		}
+		racers = append(racers, gsv.Racer{Name: a.Name, Sort: sorterFor(a, rng)})
+	}
+	race := gsv.NewRace(input, racers...)
+	switch align {
+	case "ops":
+		race.Align = gsv.AlignOps
+	case "time":
+		race.Align = gsv.AlignTime
+	default:
+		fmt.Printf("Alignment %v not found.\n", align)
+		return
+	}
+	if opts.duration > 0 {
+		race.Frames = gsv.FramesFor(opts.duration, opts.cfg.Fps)
+	}
+
+	visualizer := makeVisualizer(opts)
+	if visualizer == nil {
+		fmt.Println("Invalid visualizer name or terminal option")
+		return
+	}
+	if err := race.Play(visualizer); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// selectAlgorithms looks up the comma separated algorithms in algo or,
+// for "all", returns the registered algorithms that keep accepts
+func selectAlgorithms(algo string, keep func(gsv.Algorithm) bool) ([]gsv.Algorithm, bool) {
+	var algorithms []gsv.Algorithm
+	if algo == "all" {
+		for _, a := range gsv.Algorithms() {
+			if keep == nil || keep(a) {
+				algorithms = append(algorithms, a)
+			}
+		}
+		return algorithms, true
+	}
+	for _, name := range strings.Split(algo, ",") {
+		a, ok := gsv.Lookup(name)
+		if !ok {
+			fmt.Printf("Algorithm %v not found.\n", name)
+			return nil, false
+		}
+		algorithms = append(algorithms, a)
+	}
+	return algorithms, true
+}
+
+// sorterFor returns the sort function of a, drawing from rng if a is
+// randomized
+func sorterFor(a gsv.Algorithm, rng *rand.Rand) gsv.TraceSorter {
+	if a.Rand != nil {
+		return a.Rand(rng)
+	}
+	return a.Trace
+}
+
+// runStats counts the operations of the comma separated algorithms in
+// algo on the same input and prints them as a table in format. "all"
+// leaves out the randomized algorithms and those that cannot sort the
+// input.
+func runStats(opts options, algo, format string) {
+	input, rng, ok := makeInput(&opts)
+	if !ok {
+		return
+	}
+	algorithms, ok := selectAlgorithms(algo, func(a gsv.Algorithm) bool {
+		return a.Rand == nil && a.Check(input) == nil
+	})
+	if !ok {
+		return
+	}
+	var stats []gsv.Stats
+	for _, a := range algorithms {
+		if err := a.Check(input); err != nil {
+			fmt.Println(err)
+			return
+		}
+		stats = append(stats, gsv.Measure(a.Name, input, sorterFor(a, rng)))
+	}
+	if format == "text" {
+		fmt.Printf("input: %v, seed: %v, highest value: %v\n\n", opts.input, opts.cfg.Seed, opts.cfg.Max)
+	}
+	if err := gsv.WriteStats(os.Stdout, format, stats); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// stabilityRuns is the number of inputs runStability checks unless the
+// values are read with -from
+const stabilityRuns = 20
+
+// runStability checks whether the comma separated algorithms in algo keep
+// equal values in order, on the input and on more inputs drawn from the
+// same distribution. "all" leaves out the randomized algorithms and those
+// that cannot sort the input.
+func runStability(opts options, algo string) {
+	input, rng, ok := makeInput(&opts)
+	if !ok {
+		return
+	}
+	inputs := [][]int{input}
+	if opts.values == nil {
+		generator := opts.generator(opts.input)
+		for len(inputs) < stabilityRuns {
+			inputs = append(inputs, generator(opts.cfg.Count, opts.cfg.Max, rng))
+		}
+	}
+	algorithms, ok := selectAlgorithms(algo, func(a gsv.Algorithm) bool {
+		return a.Rand == nil && a.Check(input) == nil
+	})
+	if !ok {
+		return
+	}
+	var results []gsv.Stability
+	for _, a := range algorithms {
+		st, err := gsv.CheckStability(a.Name, inputs...)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		results = append(results, st)
+	}
+	fmt.Printf("input: %v, seed: %v, highest value: %v, runs: %v\n\n", opts.input, opts.cfg.Seed, opts.cfg.Max, len(inputs))
+	if err := gsv.WriteStability(os.Stdout, results); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// runComplexity sweeps the comma separated algorithms in algo over
+// growing sizes of each of the comma separated inputs and prints the best
+// fitting complexity. "all" leaves out algorithms limited to small values,
+// as the values grow with the sizes.
+func runComplexity(opts options, algo, sizes string) {
+	sw := gsv.Sweep{Seed: opts.cfg.Seed}
+	if sizes != "" {
+		for _, field := range strings.Split(sizes, ",") {
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 {
+				fmt.Printf("Invalid size %v.\n", field)
+				return
+			}
+			sw.Sizes = append(sw.Sizes, n)
+		}
+	}
+	if flagSet("max") {
+		sw.Max = opts.cfg.Max
+	}
+	algorithms, ok := selectAlgorithms(algo, func(a gsv.Algorithm) bool {
+		return a.MaxValue == 0
+	})
+	if !ok {
+		return
+	}
+	for _, input := range strings.Split(opts.input, ",") {
+		sw.Input = opts.generator(input)
+		if sw.Input == nil {
+			fmt.Printf("Input %v not found.\n", input)
+			return
+		}
+		fmt.Printf("input: %v, seed: %v\n\n", input, opts.cfg.Seed)
+		var results []gsv.Complexity
+		for _, a := range algorithms {
+			rng := rand.New(rand.NewSource(opts.cfg.Seed))
+			results = append(results, sw.Run(a.Name, sorterFor(a, rng)))
+		}
+		if err := gsv.WriteComplexity(os.Stdout, results); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println()
+	}
+}
+
+// options holds the command line settings of a run
+type options struct {
+	cfg      gsv.Config
+	visName  string
+	gif      gsv.GifOptions
+	input    string
+	swaps    int
+	values   []int
+	clear    string
+	color    string
+	theme    string
+	replay   string
+	origin   bool
+	every    int
+	duration time.Duration
 }
 
-func makeVisualizer(name string) gsv.Visualizer {
-	name = "gif"
-	switch name {
+// clearModes maps the values of the -clear flag
+var clearModes = map[string]gsv.ClearMode{
+	"screen": gsv.ClearScreen,
+	"home":   gsv.ClearHome,
+	"none":   gsv.ClearNone,
+	"diff":   gsv.ClearDiff,
+}
+
+func makeVisualizer(opts options) gsv.Visualizer {
+	switch opts.visName {
 	case "stdout":
-		//return &gsv.WriteStdout{}
+		clear, ok := clearModes[opts.clear]
+		color, ok2 := gsv.ColorModes[opts.color]
+		theme, ok3 := gsv.Themes[opts.theme]
+		if !ok || !ok2 || !ok3 {
+			return nil
+		}
+		sv := gsv.NewStdoutVisualizer(opts.cfg)
+		sv.Clear = clear
+		sv.Color = color
+		sv.Theme = theme
+		return sv
 	case "gif":
-		return &gsv.GifVisualizer{}
-	default:
-		return nil
+		gv := gsv.NewGifVisualizer(opts.cfg)
+		gv.GifOptions = opts.gif
+		return gv
 	}
 	return nil
 }
 
-func runSort(visName string, algo string, sortFunc gsv.Sorter) {
-	visualizer := makeVisualizer(visName)
+// generator returns the input generator called name, or nil. -swaps sets
+// the number of pairs nearlySorted swaps.
+func (opts options) generator(name string) gen.Generator {
+	if name == "nearlySorted" && opts.swaps > 0 {
+		return gen.Swapped(gen.Sorted, opts.swaps)
+	}
+	return gen.Generators[name]
+}
+
+// makeInput returns the values read with -from or generates them with
+// the generator chosen by -input from the seed of the run. Max is raised
+// if the values exceed it, e.g. for permutations. The returned source
+// continues the seeded sequence for randomized algorithms.
+func makeInput(opts *options) ([]int, *rand.Rand, bool) {
+	rng := rand.New(rand.NewSource(opts.cfg.Seed))
+	if opts.values != nil {
+		return append([]int(nil), opts.values...), rng, true
+	}
+	generator := opts.generator(opts.input)
+	if generator == nil {
+		fmt.Printf("Input %v not found.\n", opts.input)
+		return nil, nil, false
+	}
+	arr := generator(opts.cfg.Count, opts.cfg.Max, rng)
+	for _, v := range arr {
+		if v > opts.cfg.Max {
+			opts.cfg.Max = v
+		}
+	}
+	return arr, rng, true
+}
+
+// loadValues reads the values to sort from path, or stdin for "-", and
+// fits Count and Max to them. Without -max, Max is the highest value.
+func loadValues(opts *options, path string) bool {
+	var values []int
+	var err error
+	if path == "-" {
+		values, err = gsv.ReadInput(os.Stdin)
+	} else {
+		values, err = gsv.LoadInput(path)
+	}
+	if err == nil {
+		cfg := opts.cfg
+		if !flagSet("max") {
+			cfg.Max = 0
+		}
+		opts.cfg, err = cfg.ForInput(values)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	opts.values = values
+	opts.input = path
+	return true
+}
+
+// flagSet reports whether the flag name was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func runSort(opts options, a gsv.Algorithm, record string) {
+	arr, rng, ok := makeInput(&opts)
+	if !ok {
+		return
+	}
+	if err := a.Check(arr); err != nil {
+		fmt.Println(err)
+		return
+	}
+	sortFunc := sorterFor(a, rng)
+	if opts.origin {
+		if opts.visName != "gif" || record != "" || opts.duration > 0 {
+			fmt.Println("-origin needs -vis=gif and no -record or -duration")
+			return
+		}
+		origin := make([]int, len(arr))
+		s, err := gsv.OriginSorter(a.Name, origin)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		sortFunc = s
+		opts.gif.Origin = origin
+	}
+	if record != "" || opts.duration > 0 {
+		trace := gsv.Record(a.Name, arr, sortFunc)
+		trace.Seed = opts.cfg.Seed
+		if record != "" {
+			if err := gsv.SaveTrace(record, trace); err != nil {
+				fmt.Println(err)
+			}
+		}
+		replayTrace(opts, trace)
+		return
+	}
+
+	visualizer := makeVisualizer(opts)
 	if visualizer == nil {
-		fmt.Println("Invalid visualizer name")
+		fmt.Println("Invalid visualizer name or terminal option")
 		return
 	}
-	visualizer.Setup(algo)
-	arr := randomArray(gsv.Count, gsv.Max)
-	sortFunc(arr, visualizer.AddFrame)
-	visualizer.Complete()
+	if opts.every > 1 {
+		visualizer = gsv.Every(opts.every, visualizer)
+	}
+	if err := gsv.Run(visualizer, a.Name, arr, sortFunc); err != nil {
+		fmt.Println(err)
+	}
 }
 
-func keysString(m map[string]gsv.Sorter) string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
+func replayTrace(opts options, trace *gsv.Trace) {
+	visualizer := makeVisualizer(opts)
+	if visualizer == nil {
+		fmt.Println("Invalid visualizer name or terminal option")
+		return
+	}
+	if opts.duration > 0 {
+		budget := gsv.FramesFor(opts.duration, opts.cfg.Fps)
+		visualizer = gsv.Every(gsv.Stride(trace.Frames(), budget), visualizer)
+	} else if opts.every > 1 {
+		visualizer = gsv.Every(opts.every, visualizer)
+	}
+	if err := trace.Replay(visualizer); err != nil {
+		fmt.Println(err)
 	}
-	return strings.Join(keys, "/")
+}
+
+// recordPath returns the trace file for algo when several algorithms are
+// recorded at once
+func recordPath(path, algo string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + algo + ext
 }
 
 func main() {
 	var algo string
-	var visName string
-
-	sorterMap := map[string]gsv.Sorter{
-		"bubble":    gsv.BubbleSort,
-		"cocktail":  gsv.CocktailSort,
-		"comb":      gsv.CombSort,
-		"counting":  gsv.CountingSort,
-		"cycle":     gsv.CycleSort,
-		"gnome":     gsv.GnomeSort,
-		"insertion": gsv.InsertionSort,
-		"oddEven":   gsv.OddEvenSort,
-		"selection": gsv.SelectionSort,
-		"sleep":     gsv.SleepSort,
-		"stooge":    gsv.StoogeSort,
-		"pancake":   gsv.PancakeSort,
-		"quick":     gsv.QuickSort,
-		"merge":     gsv.MergeSort,
-		"shell":     gsv.ShellSort,
-		"heap":      gsv.HeapSort,
-		"radix":     gsv.RadixSort,
-		"bitonic":   gsv.BitonicSort,
-	}
-
-	flag.StringVar(&algo, "algo", "bubble", "Select sorting algorithm all/"+strings.Replace(keysString(sorterMap), "bubble", "[bubble]", 1))
-	flag.IntVar(&gsv.Fps, "fps", 10, "frames per second")
-	flag.IntVar(&gsv.Max, "max", 9, "highest value")
-	flag.IntVar(&gsv.Count, "count", 30, "number of values")
-	flag.IntVar(&gsv.Mode, "mode", 1, "visualization mode")
-	flag.StringVar(&visName, "vis", "stdout", "Select output: [stdout]/gif")
+	var record string
+	var tui bool
+	var race, align string
+	var from string
+	var stats string
+	var complexity bool
+	var sizes string
+	var stability bool
+	var list string
+	var opts options
+	cfg := &opts.cfg
+	gifOpts := &opts.gif
+
+	flag.StringVar(&algo, "algo", "bubble", "Select sorting algorithms, comma separated: all/"+strings.Replace(strings.Join(gsv.Names(), "/"), "bubble", "[bubble]", 1))
+	flag.IntVar(&cfg.Fps, "fps", 10, "frames per second")
+	flag.IntVar(&cfg.Max, "max", 9, "highest value")
+	flag.IntVar(&cfg.Count, "count", 30, "number of values")
+	flag.IntVar(&cfg.Mode, "mode", 1, "visualization mode: 1 dots, 2 bars, 3 eighth blocks, 4 braille")
+	flag.StringVar(&opts.input, "input", "random", "input distribution "+strings.Replace(strings.Join(gen.Names(), "/"), "random", "[random]", 1))
+	flag.IntVar(&opts.swaps, "swaps", 0, "pairs swapped in -input=nearlySorted, 0 swaps one in 20")
+	flag.StringVar(&from, "from", "", "read the values from a file, - for stdin (whitespace, CSV or JSON)")
+	flag.Int64Var(&cfg.Seed, "seed", 0, "seed of the input and randomized algorithms, 0 picks one")
+	flag.StringVar(&opts.visName, "vis", "stdout", "Select output: [stdout]/gif")
+	flag.StringVar(&opts.clear, "clear", "diff", "terminal redraw: [diff]/screen/home/none")
+	flag.StringVar(&opts.color, "color", "auto", "terminal colours: [auto]/none/16/256/truecolor")
+	flag.StringVar(&opts.theme, "theme", "rainbow", "terminal colour theme: [rainbow]/viridis")
+	flag.IntVar(&gifOpts.LoopCount, "loop", gsv.LoopForever, "GIF repetitions: 0 loops forever, -1 plays once")
+	flag.DurationVar(&gifOpts.Hold, "hold", 0, "time the sorted GIF frame is held")
+	flag.IntVar(&gifOpts.Width, "width", 0, "GIF width in pixels, 0 sizes by -barwidth")
+	flag.IntVar(&gifOpts.Height, "height", 0, "GIF height in pixels, 0 sizes by -valueheight")
+	flag.IntVar(&gifOpts.BarWidth, "barwidth", 1, "GIF bar width in pixels")
+	flag.IntVar(&gifOpts.ValueHeight, "valueheight", 1, "GIF height of one value step in pixels")
+	flag.IntVar(&gifOpts.Gap, "gap", 0, "GIF space between bars in pixels")
+	flag.IntVar(&gifOpts.Margin, "margin", 0, "GIF space around the bars in pixels")
+	flag.BoolVar(&gifOpts.Axis, "axis", false, "draw a value axis in GIFs")
+	flag.BoolVar(&opts.origin, "origin", false, "colour GIF bars by their position before sorting")
+	flag.StringVar(&record, "record", "", "record the run to a trace file (.json for JSON)")
+	flag.StringVar(&opts.replay, "replay", "", "replay a recorded trace file instead of sorting")
+	flag.IntVar(&opts.every, "every", 1, "keep only every nth frame")
+	flag.DurationVar(&opts.duration, "duration", 0, "fit the animation into this duration at -fps")
+
+	flag.StringVar(&stats, "stats", "", "print operation counts instead of sorting visibly: "+strings.Join(gsv.StatsFormats, "/"))
+	flag.BoolVar(&complexity, "complexity", false, "fit the growth of the operations over -sizes for each of the comma separated -input")
+	flag.StringVar(&sizes, "sizes", "", "comma separated sizes for -complexity, default 16,64,...,65536")
+	flag.BoolVar(&stability, "stability", false, "check whether equal values keep their order instead of sorting visibly")
+	flag.StringVar(&list, "list", "", "list the algorithms and their properties as text/markdown")
+	flag.BoolVar(&tui, "tui", false, "start the interactive player")
+	flag.StringVar(&race, "race", "", "race comma separated algorithms on the same input, e.g. bubble,quick")
+	flag.StringVar(&align, "align", "ops", "align races by [ops]/time")
 
 	flag.Parse()
+	if list != "" {
+		if err := gsv.WriteAlgorithms(os.Stdout, list); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
+	if cfg.Seed == 0 && !tui {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	if from != "" {
+		if !loadValues(&opts, from) {
+			return
+		}
+	}
 
-	fmt.Printf("sorting via %v-sort\nhighest value: %v\nnumber of values: %v\n\n", algo, gsv.Max, gsv.Count)
-	time.Sleep(time.Second * 1)
-	if algo == "all" {
-		for k, v := range sorterMap {
-			runSort(visName, k, v)
+	if tui {
+		runTUI(opts)
+		return
+	}
+	if complexity {
+		runComplexity(opts, algo, sizes)
+		return
+	}
+	if stats != "" {
+		runStats(opts, algo, stats)
+		return
+	}
+	if stability {
+		runStability(opts, algo)
+		return
+	}
+	if race != "" {
+		runRace(opts, race, align)
+		return
+	}
+
+	if opts.replay != "" {
+		trace, err := gsv.LoadTrace(opts.replay)
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
-	} else {
-		sortFunc := sorterMap[algo]
-		if sortFunc != nil {
-			runSort(visName, algo, sortFunc)
-		} else {
-			fmt.Printf("Algorithm %v not found.\n", algo)
+		replayCfg := opts.cfg
+		if !flagSet("max") {
+			replayCfg.Max = 0
+		}
+		if opts.cfg, err = replayCfg.ForInput(trace.Input); err != nil {
+			fmt.Println(err)
+			return
+		}
+		cfg.Seed = trace.Seed
+		replayTrace(opts, trace)
+		return
+	}
+
+	fmt.Printf("sorting via %v-sort\ninput: %v\nseed: %v\nhighest value: %v\nnumber of values: %v\n\n", algo, opts.input, cfg.Seed, cfg.Max, cfg.Count)
+	time.Sleep(time.Second * 1)
+	algorithms, ok := selectAlgorithms(algo, func(a gsv.Algorithm) bool {
+		return a.Rand == nil
+	})
+	if !ok {
+		return
+	}
+	for _, a := range algorithms {
+		path := record
+		if path != "" && len(algorithms) > 1 {
+			path = recordPath(record, a.Name)
 		}
+		runSort(opts, a, path)
 	}
 }
@@ -0,0 +1,55 @@
+package gsv
+
+import (
+	"image"
+	"strings"
+)
+
+// font3x5 is a tiny font for labels in GIFs. Each glyph has five rows of
+// three pixels; in a row, 4 is the left, 2 the middle and 1 the right one.
+var font3x5 = map[rune][5]uint8{
+	'A': {2, 5, 7, 5, 5}, 'B': {6, 5, 6, 5, 6}, 'C': {3, 4, 4, 4, 3},
+	'D': {6, 5, 5, 5, 6}, 'E': {7, 4, 6, 4, 7}, 'F': {7, 4, 6, 4, 4},
+	'G': {3, 4, 5, 5, 3}, 'H': {5, 5, 7, 5, 5}, 'I': {7, 2, 2, 2, 7},
+	'J': {1, 1, 1, 5, 2}, 'K': {5, 5, 6, 5, 5}, 'L': {4, 4, 4, 4, 7},
+	'M': {5, 7, 7, 5, 5}, 'N': {6, 5, 5, 5, 5}, 'O': {2, 5, 5, 5, 2},
+	'P': {6, 5, 6, 4, 4}, 'Q': {2, 5, 5, 6, 3}, 'R': {6, 5, 6, 5, 5},
+	'S': {3, 4, 2, 1, 6}, 'T': {7, 2, 2, 2, 2}, 'U': {5, 5, 5, 5, 7},
+	'V': {5, 5, 5, 5, 2}, 'W': {5, 5, 7, 7, 5}, 'X': {5, 5, 2, 5, 5},
+	'Y': {5, 5, 2, 2, 2}, 'Z': {7, 1, 2, 4, 7},
+	'0': {7, 5, 5, 5, 7}, '1': {2, 6, 2, 2, 7}, '2': {6, 1, 2, 4, 7},
+	'3': {6, 1, 2, 1, 6}, '4': {5, 5, 7, 1, 1}, '5': {7, 4, 6, 1, 6},
+	'6': {3, 4, 7, 5, 7}, '7': {7, 1, 2, 2, 2}, '8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 6},
+	' ': {}, '.': {0, 0, 0, 0, 2}, ':': {0, 2, 0, 2, 0}, '-': {0, 0, 7, 0, 0},
+	'#': {5, 7, 5, 7, 5}, '/': {1, 1, 2, 4, 4}, '_': {0, 0, 0, 0, 7},
+	'?': {6, 1, 2, 0, 2},
+}
+
+// Size of a glyph of font3x5 including the space after it
+const (
+	glyphWidth  = 4
+	glyphHeight = 6
+)
+
+// drawLabel writes text in upper case at x, y with glyphs scaled by scale,
+// clipped to the width w
+func drawLabel(img *image.Paletted, x, y, w int, text string, scale int, c uint8) {
+	text = strings.ToUpper(strings.ReplaceAll(text, "µ", "u"))
+	clip := image.Rect(x, y, x+w, y+glyphHeight*scale).Intersect(img.Rect)
+	for _, r := range text {
+		glyph, ok := font3x5[r]
+		if !ok {
+			glyph = font3x5['?']
+		}
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>col) != 0 {
+					px := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+					fillRect(img, px.Intersect(clip), c)
+				}
+			}
+		}
+		x += glyphWidth * scale
+	}
+}
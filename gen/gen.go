@@ -0,0 +1,239 @@
+// Package gen generates input arrays for the sorting visualizations.
+// Besides uniform random values it offers sorted, structured and
+// adversarial inputs to show how algorithms react to them.
+package gen
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Generator returns n values between 0 and max. Generators that need
+// randomness draw it from rng; a nil rng uses a time-seeded source.
+type Generator func(n, max int, rng *rand.Rand) []int
+
+// Generators maps names, e.g. of command line flags, to generators
+var Generators = map[string]Generator{
+	"random":       Random,
+	"sorted":       Sorted,
+	"reversed":     Reversed,
+	"nearlySorted": NearlySorted,
+	"fewUnique":    FewUnique,
+	"sawtooth":     Sawtooth,
+	"organPipe":    OrganPipe,
+	"gaussian":     Gaussian,
+	"allEqual":     AllEqual,
+	"permutation":  Permutation,
+	"killer":       Killer,
+}
+
+// Names returns the names of Generators in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(Generators))
+	for name := range Generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// source returns rng or a new time-seeded source if rng is nil
+func source(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rng
+}
+
+// level returns the value of step i of n steps spread evenly from 1 to max
+func level(i, n, max int) int {
+	if n < 2 || max < 2 {
+		return max
+	}
+	return 1 + i*(max-1)/(n-1)
+}
+
+// Random returns uniformly distributed values from 0 to max
+func Random(n, max int, rng *rand.Rand) []int {
+	rng = source(rng)
+	arr := make([]int, n)
+	for i := range arr {
+		arr[i] = rng.Intn(max + 1)
+	}
+	return arr
+}
+
+// Sorted returns values rising evenly from 1 to max
+func Sorted(n, max int, rng *rand.Rand) []int {
+	arr := make([]int, n)
+	for i := range arr {
+		arr[i] = level(i, n, max)
+	}
+	return arr
+}
+
+// Reversed returns values falling evenly from max to 1
+func Reversed(n, max int, rng *rand.Rand) []int {
+	arr := make([]int, n)
+	for i := range arr {
+		arr[i] = level(n-1-i, n, max)
+	}
+	return arr
+}
+
+// Swapped returns a generator that swaps k random pairs of the values
+// of g
+func Swapped(g Generator, k int) Generator {
+	return func(n, max int, rng *rand.Rand) []int {
+		rng = source(rng)
+		arr := g(n, max, rng)
+		for swaps := k; swaps > 0 && n > 1; swaps-- {
+			i, j := rng.Intn(n), rng.Intn(n)
+			arr[i], arr[j] = arr[j], arr[i]
+		}
+		return arr
+	}
+}
+
+// NearlySorted returns Sorted values with one random pair in 20 swapped;
+// Swapped(Sorted, k) swaps any other number of pairs
+func NearlySorted(n, max int, rng *rand.Rand) []int {
+	return Swapped(Sorted, (n+19)/20)(n, max, rng)
+}
+
+// FewUnique returns random values taken from five distinct levels
+func FewUnique(n, max int, rng *rand.Rand) []int {
+	rng = source(rng)
+	k := 5
+	if max < k {
+		k = max
+	}
+	arr := make([]int, n)
+	for i := range arr {
+		arr[i] = level(rng.Intn(k), k, max)
+	}
+	return arr
+}
+
+// Sawtooth returns four runs rising from 1 to max
+func Sawtooth(n, max int, rng *rand.Rand) []int {
+	tooth := (n + 3) / 4
+	arr := make([]int, n)
+	for i := range arr {
+		arr[i] = level(i%tooth, tooth, max)
+	}
+	return arr
+}
+
+// OrganPipe returns values rising to max in the first half and falling
+// again in the second
+func OrganPipe(n, max int, rng *rand.Rand) []int {
+	half := (n + 1) / 2
+	arr := make([]int, n)
+	for i := range arr {
+		k := i
+		if i >= half {
+			k = n - 1 - i
+		}
+		arr[i] = level(k, half, max)
+	}
+	return arr
+}
+
+// Gaussian returns normally distributed values around max/2 with a
+// standard deviation of max/6, limited to 0 to max
+func Gaussian(n, max int, rng *rand.Rand) []int {
+	rng = source(rng)
+	arr := make([]int, n)
+	for i := range arr {
+		v := math.Round(float64(max)/2 + rng.NormFloat64()*float64(max)/6)
+		arr[i] = int(math.Max(0, math.Min(float64(max), v)))
+	}
+	return arr
+}
+
+// AllEqual returns n times the value max/2, but at least 1
+func AllEqual(n, max int, rng *rand.Rand) []int {
+	v := max / 2
+	if v < 1 {
+		v = max
+	}
+	arr := make([]int, n)
+	for i := range arr {
+		arr[i] = v
+	}
+	return arr
+}
+
+// Permutation returns the numbers 1 to n in random order. max is ignored,
+// so the values should be drawn with a Max of n.
+func Permutation(n, max int, rng *rand.Rand) []int {
+	rng = source(rng)
+	arr := make([]int, n)
+	for i, j := range rng.Perm(n) {
+		arr[i] = j + 1
+	}
+	return arr
+}
+
+// Killer returns an input that makes QuickSort, which partitions around
+// the last element, take quadratic time without being sorted already. It
+// is built with McIlroy's adversary ("A Killer Adversary for Quicksort",
+// 1999): while a quicksort runs on undecided values, every value is fixed
+// as late as possible, and as small as possible once it is compared to
+// the pivot candidate. The values are spread from 1 to max.
+func Killer(n, max int, rng *rand.Rand) []int {
+	gas := n
+	val := make([]int, n)
+	for i := range val {
+		val[i] = gas
+	}
+	solid, candidate := 0, -1
+	less := func(x, y int) bool {
+		if val[x] == gas && val[y] == gas {
+			if x == candidate {
+				val[x], solid = solid, solid+1
+			} else {
+				val[y], solid = solid, solid+1
+			}
+		}
+		if val[x] == gas {
+			candidate = x
+		} else if val[y] == gas {
+			candidate = y
+		}
+		return val[x] < val[y]
+	}
+
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = i
+	}
+	lomuto(ids, 0, n-1, less)
+
+	arr := make([]int, n)
+	for i, v := range val {
+		arr[i] = level(v, n+1, max)
+	}
+	return arr
+}
+
+// lomuto sorts ids like the QuickSort of the gsv package, comparing with
+// less instead of the values
+func lomuto(ids []int, l, r int, less func(x, y int) bool) {
+	for l < r {
+		pivot := ids[r]
+		i := l
+		for j := l; j < r; j++ {
+			if !less(pivot, ids[j]) {
+				ids[i], ids[j] = ids[j], ids[i]
+				i++
+			}
+		}
+		ids[i], ids[r] = ids[r], ids[i]
+		lomuto(ids, l, i-1, less)
+		l = i + 1
+	}
+}
@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestGenerators checks the length and the range of all generators.
+func TestGenerators(t *testing.T) {
+	for _, name := range Names() {
+		for _, n := range []int{0, 1, 7, 100} {
+			arr := Generators[name](n, 20, rand.New(rand.NewSource(1)))
+			if len(arr) != n {
+				t.Errorf("%s: Expected %d values, got %d", name, n, len(arr))
+			}
+			max := 20
+			if name == "permutation" {
+				max = n
+			}
+			for _, v := range arr {
+				if v < 0 || v > max {
+					t.Errorf("%s: Expected values from 0 to %d, got %d", name, max, v)
+					break
+				}
+			}
+		}
+	}
+}
+
+// TestShapes checks the order of the structured inputs.
+func TestShapes(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ascending := func(arr []int) bool { return sort.IntsAreSorted(arr) }
+	descending := func(arr []int) bool {
+		return sort.SliceIsSorted(arr, func(i, j int) bool { return arr[i] > arr[j] })
+	}
+
+	if arr := Sorted(10, 9, rng); !ascending(arr) || arr[0] != 1 || arr[9] != 9 {
+		t.Errorf("Sorted: got %v", arr)
+	}
+	if arr := Reversed(10, 9, rng); !descending(arr) || arr[0] != 9 || arr[9] != 1 {
+		t.Errorf("Reversed: got %v", arr)
+	}
+	if arr := OrganPipe(10, 9, rng); !ascending(arr[:5]) || !descending(arr[5:]) {
+		t.Errorf("OrganPipe: got %v", arr)
+	}
+	if arr := Sawtooth(12, 9, rng); !ascending(arr[:3]) || !ascending(arr[9:]) || arr[3] >= arr[2] {
+		t.Errorf("Sawtooth: got %v", arr)
+	}
+	if arr := AllEqual(5, 9, rng); !reflect.DeepEqual(arr, []int{4, 4, 4, 4, 4}) {
+		t.Errorf("AllEqual: got %v", arr)
+	}
+
+	distinct := map[int]bool{}
+	for _, v := range FewUnique(100, 50, rng) {
+		distinct[v] = true
+	}
+	if len(distinct) > 5 {
+		t.Errorf("FewUnique: Expected at most 5 values, got %d", len(distinct))
+	}
+
+	arr := NearlySorted(100, 100, rng)
+	moved := 0
+	for i, v := range Sorted(100, 100, rng) {
+		if arr[i] != v {
+			moved++
+		}
+	}
+	if moved == 0 || moved > 10 {
+		t.Errorf("NearlySorted: Expected 1 to 10 moved values, got %d", moved)
+	}
+
+	arr = Permutation(50, 0, rng)
+	sort.Ints(arr)
+	for i, v := range arr {
+		if v != i+1 {
+			t.Fatalf("Permutation: Expected the numbers 1 to 50, got %v", arr)
+		}
+	}
+}
+
+// TestSeeded checks that a seeded source makes the random inputs
+// reproducible.
+func TestSeeded(t *testing.T) {
+	for _, name := range []string{"random", "gaussian", "fewUnique", "nearlySorted", "permutation"} {
+		a := Generators[name](50, 20, rand.New(rand.NewSource(42)))
+		b := Generators[name](50, 20, rand.New(rand.NewSource(42)))
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: Expected the same values for the same seed", name)
+		}
+	}
+}
+
+// TestSwappedReuse checks that a Swapped generator swaps values on every
+// call, not only on the first.
+func TestSwappedReuse(t *testing.T) {
+	g := Swapped(Sorted, 5)
+	rng := rand.New(rand.NewSource(1))
+	for call := 0; call < 2; call++ {
+		arr := g(50, 50, rng)
+		if sort.IntsAreSorted(arr) {
+			t.Errorf("call %d: Expected swapped values, got %v", call, arr)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package gsv
+
+import (
+	"bufio"
+	"compress/lzw"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// disposalNone keeps a GIF frame in place when the next one is drawn
+const disposalNone = 1
+
+// gifWriter encodes a GIF animation frame by frame instead of holding all
+// frames in memory like gif.EncodeAll. All frames share the global palette.
+type gifWriter struct {
+	w        *bufio.Writer
+	litWidth int
+	blocks   blockWriter
+	err      error
+}
+
+// newGifWriter writes the GIF header for an animation of the given size.
+// loopCount has the meaning of gif.GIF.LoopCount.
+func newGifWriter(w io.Writer, width, height int, p color.Palette, loopCount int) *gifWriter {
+	e := &gifWriter{w: bufio.NewWriter(w)}
+	if width > 0xffff || height > 0xffff {
+		e.err = errors.New("gsv: image is too large to encode")
+		return e
+	}
+
+	sizeBits := 0
+	for 1<<(sizeBits+1) < len(p) {
+		sizeBits++
+	}
+	e.litWidth = sizeBits + 1
+	if e.litWidth < 2 {
+		e.litWidth = 2
+	}
+
+	e.write([]byte("GIF89a"))
+	e.writeUint16(width)
+	e.writeUint16(height)
+	e.write([]byte{0x80 | 0x70 | byte(sizeBits), 0, 0})
+	table := make([]byte, 3<<(sizeBits+1))
+	for i, c := range p {
+		r, g, b, _ := c.RGBA()
+		table[3*i], table[3*i+1], table[3*i+2] = byte(r>>8), byte(g>>8), byte(b>>8)
+	}
+	e.write(table)
+
+	if loopCount >= 0 {
+		e.write([]byte{0x21, 0xff, 0x0b})
+		e.write([]byte("NETSCAPE2.0"))
+		e.write([]byte{0x03, 0x01})
+		e.writeUint16(loopCount)
+		e.write([]byte{0x00})
+	}
+	return e
+}
+
+// writeFrame appends m, placed at m.Rect, with a delay in 1/100 s. A
+// transparent index below 0 leaves the frame opaque.
+func (e *gifWriter) writeFrame(m *image.Paletted, delay int, disposal byte, transparent int) {
+	if delay > 0xffff {
+		delay = 0xffff
+	}
+	flags := disposal << 2
+	if transparent >= 0 {
+		flags |= 0x01
+	} else {
+		transparent = 0
+	}
+	e.write([]byte{0x21, 0xf9, 0x04, flags})
+	e.writeUint16(delay)
+	e.write([]byte{byte(transparent), 0x00})
+
+	b := m.Bounds()
+	e.write([]byte{0x2c})
+	e.writeUint16(b.Min.X)
+	e.writeUint16(b.Min.Y)
+	e.writeUint16(b.Dx())
+	e.writeUint16(b.Dy())
+	e.write([]byte{0x00, byte(e.litWidth)})
+	if e.err != nil {
+		return
+	}
+
+	e.blocks.w = e.w
+	e.blocks.n = 0
+	lw := lzw.NewWriter(&e.blocks, lzw.LSB, e.litWidth)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		if _, err := lw.Write(m.Pix[m.PixOffset(b.Min.X, y):m.PixOffset(b.Max.X, y)]); err != nil {
+			e.err = err
+			return
+		}
+	}
+	if err := lw.Close(); err != nil {
+		e.err = err
+		return
+	}
+	if err := e.blocks.flush(); err != nil {
+		e.err = err
+		return
+	}
+	e.write([]byte{0x00})
+}
+
+// writeComment appends a comment extension
+func (e *gifWriter) writeComment(text string) {
+	e.write([]byte{0x21, 0xfe})
+	for len(text) > 0 {
+		n := len(text)
+		if n > 255 {
+			n = 255
+		}
+		e.write([]byte{byte(n)})
+		e.write([]byte(text[:n]))
+		text = text[n:]
+	}
+	e.write([]byte{0x00})
+}
+
+// close writes the trailer and returns the first error that occurred
+func (e *gifWriter) close() error {
+	e.write([]byte{0x3b})
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.err
+}
+
+func (e *gifWriter) write(p []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+}
+
+func (e *gifWriter) writeUint16(v int) {
+	e.write([]byte{byte(v), byte(v >> 8)})
+}
+
+// blockWriter splits the LZW stream into GIF data sub-blocks
+type blockWriter struct {
+	w   io.Writer
+	buf [256]byte
+	n   int
+}
+
+func (b *blockWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		k := copy(b.buf[1+b.n:], p)
+		b.n += k
+		p = p[k:]
+		written += k
+		if b.n == 255 {
+			if err := b.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// flush writes the buffered bytes as one sub-block
+func (b *blockWriter) flush() error {
+	if b.n == 0 {
+		return nil
+	}
+	b.buf[0] = byte(b.n)
+	_, err := b.w.Write(b.buf[:1+b.n])
+	b.n = 0
+	return err
+}
@@ -0,0 +1,195 @@
+package gsv
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestGifTiming checks that frame delays follow the frame rate and that
+// the loop and hold options end up in the GIF.
+func TestGifTiming(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "timing")
+	gv := NewGifVisualizer(Config{Max: 9, Fps: 30})
+	gv.LoopCount = LoopOnce
+	gv.Hold = 2 * time.Second
+	gv.Setup(name)
+	for i := 0; i < 30; i++ {
+		gv.AddFrame([]int{1, i % 9, 3})
+	}
+	if err := gv.Complete(); err != nil {
+		t.Fatal(err)
+	}
+
+	g := decodeGif(t, name)
+	if g.LoopCount != LoopOnce {
+		t.Errorf("Expected loop count %d, got %d", LoopOnce, g.LoopCount)
+	}
+	if len(g.Delay) != 30 {
+		t.Fatalf("Expected 30 frames, got %d", len(g.Delay))
+	}
+
+	total := 0
+	for _, d := range g.Delay[:29] {
+		if d < 3 || d > 4 {
+			t.Errorf("Expected delays of 3 or 4, got %d", d)
+		}
+		total += d
+	}
+	if last := g.Delay[29]; last < 203 || last > 204 {
+		t.Errorf("Expected the final frame to be held for 200 1/100 s more, got %d", last)
+	} else {
+		total += last - 200
+	}
+	if total != 100 {
+		t.Errorf("Expected 30 frames at 30 fps to last 100 1/100 s, got %d", total)
+	}
+}
+
+// TestGifStream checks that the streaming encoder writes the frames the
+// visualizer built, merging repeated ones.
+func TestGifStream(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "stream")
+	cfg := Config{Max: 300, Mode: 2}
+	gv := NewGifVisualizer(cfg)
+	gv.Setup(name)
+	arr := gen.Random(300, 300, nil)
+	var frames []*image.Paletted
+	ShellSortTraced(arr, TraceFunc(func(e Event) {
+		if e.Mutates() && len(frames) < 50 {
+			gv.AddFrame(arr)
+			if len(frames) == 0 || frames[len(frames)-1] != gv.pending {
+				frames = append(frames, gv.pending)
+			}
+		}
+	}))
+	gv.AddFrame(arr)
+	gv.AddFrame(arr)
+	if err := gv.Complete(); err != nil {
+		t.Fatal(err)
+	}
+
+	g := decodeGif(t, name)
+	if len(g.Image) != len(frames)+1 {
+		t.Fatalf("Expected %d frames, got %d", len(frames)+1, len(g.Image))
+	}
+	if d := g.Delay[len(g.Delay)-1]; d != 2*frameDelay(0, 0) {
+		t.Errorf("Expected repeated frames to be merged, got delay %d", d)
+	}
+	canvas := image.NewPaletted(g.Image[0].Rect, g.Image[0].Palette)
+	for k, frame := range frames {
+		sub := g.Image[k]
+		for y := sub.Rect.Min.Y; y < sub.Rect.Max.Y; y++ {
+			for x := sub.Rect.Min.X; x < sub.Rect.Max.X; x++ {
+				if c := sub.ColorIndexAt(x, y); int(c) != gifTransparent {
+					canvas.SetColorIndex(x, y, c)
+				}
+			}
+		}
+		if !bytes.Equal(frame.Pix, canvas.Pix) {
+			t.Errorf("Frame %d differs from the encoded one", k)
+		}
+		if k > 0 && sub.Rect.Dx() != 1 {
+			t.Errorf("Expected frame %d to only cover the written bar, got %v", k, sub.Rect)
+		}
+	}
+}
+
+// failingWriter accepts a number of bytes and fails after that
+type failingWriter struct {
+	n int
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > fw.n {
+		return 0, errors.New("disk full")
+	}
+	fw.n -= len(p)
+	return len(p), nil
+}
+
+// TestGifErrors checks that output failures are returned instead of
+// panicking and that they stop the sort.
+func TestGifErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Run(NewGifWriter(&buf, Config{Max: 9}), "ok", gen.Random(9, 9, nil), QuickSortTraced); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gif.DecodeAll(&buf); err != nil {
+		t.Errorf("Expected a valid GIF, got %v", err)
+	}
+
+	buf.Reset()
+	done := NewGifWriter(&buf, Config{Max: 9})
+	if err := Run(done, "twice", []int{2, 1}, BubbleSortTraced); err != nil {
+		t.Fatal(err)
+	}
+	size := buf.Len()
+	if err := done.Complete(); err != nil {
+		t.Errorf("Expected a second Complete to succeed, got %v", err)
+	}
+	if err := done.AddFrame([]int{1, 2}); err == nil || !strings.Contains(err.Error(), "after Complete") {
+		t.Errorf("Expected AddFrame after Complete to fail, got %v", err)
+	}
+	if buf.Len() != size {
+		t.Errorf("Expected nothing to be written after Complete, got %d more bytes", buf.Len()-size)
+	}
+	if _, err := gif.DecodeAll(&buf); err != nil {
+		t.Errorf("Expected a valid GIF after a second Complete, got %v", err)
+	}
+
+	ops := 0
+	counter := TraceFunc(func(Event) { ops++ })
+	err := Run(NewGifWriter(&failingWriter{n: 100}, Config{Max: 9}), "full", gen.Random(300, 9, nil), func(arr []int, t Tracer) {
+		BubbleSortTraced(arr, MultiTracer(t, counter))
+	})
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Expected the write error, got %v", err)
+	}
+	if ops > 300*299/10 {
+		t.Errorf("Expected the sort to stop after the error, got %d operations", ops)
+	}
+
+	gv := NewGifVisualizer(Config{Max: 9})
+	if err := gv.Setup(filepath.Join(t.TempDir(), "missing", "dir")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+	if err := gv.AddFrame([]int{1}); err == nil {
+		t.Error("Expected the error to be kept")
+	}
+	if err := gv.Complete(); err == nil {
+		t.Error("Expected Complete to report the error")
+	}
+
+	for _, gv := range []*GifVisualizer{{}, NewGifWriter(&buf, Config{Max: 9})} {
+		if err := gv.AddFrame([]int{1}); err == nil {
+			t.Error("Expected AddFrame before Setup to fail")
+		}
+		if err := gv.Complete(); err == nil || !strings.Contains(err.Error(), "before Setup") {
+			t.Errorf("Expected Complete before Setup to fail, got %v", err)
+		}
+	}
+}
+
+// decodeGif reads the GIF written for name
+func decodeGif(t *testing.T, name string) *gif.GIF {
+	t.Helper()
+	f, err := os.Open(name + ".gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
@@ -2,10 +2,13 @@ package gsv
 
 import (
 	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/gif"
+	"io"
 	"math/rand"
 	"os"
 	"time"
@@ -17,107 +20,578 @@ type Sorter func([]int, FrameGen)
 // FrameGen defines a function type for generating frames
 type FrameGen func([]int)
 
-//var test bool = false
-
-func (fg FrameGen) Setup(name string) {
+func (fg FrameGen) Setup(name string) error {
+	return nil
 }
 
-func (fg FrameGen) AddFrame(arr []int) {
+func (fg FrameGen) AddFrame(arr []int) error {
 	fg(arr)
+	return nil
 }
 
-func (fg FrameGen) Complete() {
+func (fg FrameGen) Complete() error {
+	return nil
 }
 
-// Visualizer interface for visualizing sorting steps
+// Visualizer interface for visualizing sorting steps. Once a method
+// failed, later calls should keep returning the error, so it is reported
+// by Complete at the latest.
 type Visualizer interface {
-	Setup(string)
-	AddFrame([]int)
-	Complete()
+	Setup(string) error
+	AddFrame([]int) error
+	Complete() error
+}
+
+// EventVisualizer is a Visualizer that also receives the operations
+// leading to each frame, for example to highlight them
+type EventVisualizer interface {
+	Visualizer
+	AddEvent(Event)
+}
+
+// Config holds the settings of a single visualization run. Several runs
+// with different Configs can be active in the same process.
+type Config struct {
+	// Max is the highest value an element can take.
+	Max int
+	// Fps is the number of frames shown per second.
+	Fps int
+	// Count is the number of values to sort.
+	Count int
+	// Mode selects the visualization mode, e.g. ModeDots or ModeBars.
+	Mode int
+	// Quiet suppresses terminal output and frame pacing.
+	Quiet bool
+	// Seed, if not 0, is the seed the input and randomized algorithms of
+	// the run draw from. GIFs record it in a comment.
+	Seed int64
+}
+
+// Values for Config.Mode
+const (
+	// ModeDots draws a dot at the value of each element
+	ModeDots = 1
+	// ModeBars draws filled bars
+	ModeBars = 2
+	// ModeBlocks draws filled bars with eighth blocks, so a terminal row
+	// shows 8 value steps. GIFs draw it like ModeBars.
+	ModeBlocks = 3
+	// ModeBraille draws filled bars with Braille patterns, so a terminal
+	// cell shows 2 elements and 4 value steps. GIFs draw it like ModeBars.
+	ModeBraille = 4
+)
+
+// Max is the default for Config.Max.
+//
+// Deprecated: set Config.Max instead.
+var Max = 9
+
+// Fps is the default for Config.Fps.
+//
+// Deprecated: set Config.Fps instead.
+var Fps = 10
+
+// Count is the default for Config.Count.
+//
+// Deprecated: set Config.Count instead.
+var Count = 30
+
+// Mode is the default for Config.Mode.
+//
+// Deprecated: set Config.Mode instead.
+var Mode = 1
+
+// DefaultConfig returns a Config populated from the package-level defaults
+func DefaultConfig() Config {
+	return Config{
+		Max:   Max,
+		Fps:   Fps,
+		Count: Count,
+		Mode:  Mode,
+	}
 }
 
-// GifVisualizer is a visualizer that outputs a GIF
+// Values for GifOptions.LoopCount, as defined by gif.GIF.LoopCount
+const (
+	// LoopForever repeats the animation endlessly
+	LoopForever = 0
+	// LoopOnce plays the animation a single time
+	LoopOnce = -1
+)
+
+// GifOptions holds the settings that only apply to GIF output
+type GifOptions struct {
+	// LoopCount is LoopForever, LoopOnce or the number of times the
+	// animation is repeated after it was played once.
+	LoopCount int
+	// Hold keeps the final frame on screen for the given time before
+	// the animation loops or ends.
+	Hold time.Duration
+	// BarWidth is the width of a bar and ValueHeight the height of one
+	// value step in pixels. Both default to 1.
+	BarWidth    int
+	ValueHeight int
+	// Width and Height, if set, give the size of the image in pixels.
+	// Bars and value steps are then scaled to fill it.
+	Width  int
+	Height int
+	// Gap is the space between two bars in pixels
+	Gap int
+	// Margin is the space around the bars in pixels
+	Margin int
+	// Axis draws a value axis with ticks left of the bars and a baseline
+	// below them
+	Axis bool
+	// Origin, if set, holds the index each bar had before sorting. Bars
+	// are then coloured by it, so runs of equal values show whether they
+	// kept their order. It is read at every frame; see OriginSorter.
+	Origin []int
+}
+
+// axisSpace is the room taken by the value axis left of and below the bars
+const axisSpace = 4
+
+// layout holds the pixel geometry of a frame
+type layout struct {
+	barWidth    int
+	valueHeight int
+	gap         int
+	left        int
+	right       int
+	top         int
+	width       int
+	height      int
+}
+
+// layout computes the geometry of a frame showing n values up to max
+func (o GifOptions) layout(n, max int) layout {
+	l := layout{
+		barWidth:    o.BarWidth,
+		valueHeight: o.ValueHeight,
+		gap:         o.Gap,
+		left:        o.Margin,
+		top:         o.Margin,
+	}
+	if o.Axis {
+		l.left += axisSpace
+	}
+	extraWidth := l.left + o.Margin
+	extraHeight := 2 * o.Margin
+	if o.Axis {
+		extraHeight += axisSpace / 2
+	}
+	if o.Width > 0 && n > 0 {
+		l.barWidth = (o.Width - extraWidth - (n-1)*l.gap) / n
+	}
+	if o.Height > 0 && max > 0 {
+		l.valueHeight = (o.Height - extraHeight) / max
+	}
+	if l.barWidth < 1 {
+		l.barWidth = 1
+	}
+	if l.valueHeight < 1 {
+		l.valueHeight = 1
+	}
+	l.right = l.left
+	if n > 0 {
+		l.right += n*l.barWidth + (n-1)*l.gap
+	}
+	l.width = l.right + o.Margin
+	l.height = max*l.valueHeight + extraHeight
+	if o.Width > l.width {
+		l.width = o.Width
+	}
+	if o.Height > l.height {
+		l.height = o.Height
+	}
+	return l
+}
+
+// GifVisualizer is a visualizer that outputs a GIF. Frames are encoded as
+// they arrive, so memory use does not grow with the length of the run.
+// Only the part of a frame that changed is written, and identical
+// consecutive frames are merged into one with a longer delay.
 type GifVisualizer struct {
-	name string
-	g    *gif.GIF
+	GifOptions
+	name    string
+	cfg     *Config
+	hl      highlight
+	frames  int
+	w       io.Writer
+	out     io.Writer
+	closer  io.Closer
+	enc     *gifWriter
+	canvas  *image.Paletted
+	pending *image.Paletted
+	delay   int
+	done    bool
+	err     error
 }
 
-var Max int
-var Fps int
-var Count int
-var Mode int
-var test bool = false
+// NewGifVisualizer returns a GIF visualizer that renders with cfg and
+// writes each run to a file named after it.
+// A zero GifVisualizer uses DefaultConfig at Setup time.
+func NewGifVisualizer(cfg Config) *GifVisualizer {
+	return &GifVisualizer{cfg: &cfg}
+}
+
+// NewGifWriter returns a GIF visualizer that renders with cfg and writes
+// the animation to w instead of a file. w is not closed by Complete.
+func NewGifWriter(w io.Writer, cfg Config) *GifVisualizer {
+	return &GifVisualizer{cfg: &cfg, w: w}
+}
 
-// Setup initializes the GIF visualizer
-func (gv *GifVisualizer) Setup(name string) {
-	gv.g = &gif.GIF{
-		LoopCount: 1,
+// Setup prepares the GIF output for the run called name
+func (gv *GifVisualizer) Setup(name string) error {
+	if gv.cfg == nil {
+		cfg := DefaultConfig()
+		gv.cfg = &cfg
 	}
 	gv.name = name
+	gv.hl.reset()
+	gv.frames = 0
+	gv.enc = nil
+	gv.canvas = nil
+	gv.pending = nil
+	gv.done = false
+	gv.err = nil
+	gv.out, gv.closer = gv.w, nil
+	if gv.w == nil {
+		f, err := os.Create(name + ".gif")
+		if err != nil {
+			gv.err = fmt.Errorf("gsv: %w", err)
+			return gv.err
+		}
+		gv.out, gv.closer = f, f
+	}
+	return nil
 }
 
-// AddFrame adds a frame to the GIF
-func (gv *GifVisualizer) AddFrame(arr []int) {
-	frame := buildImage(arr)
-	gv.g.Image = append(gv.g.Image, frame)
-	gv.g.Delay = append(gv.g.Delay, 2)
+// AddEvent records an operation to be highlighted in the next frame
+func (gv *GifVisualizer) AddEvent(e Event) {
+	gv.hl.add(e)
 }
 
-// Complete writes the GIF to disk
-func (gv *GifVisualizer) Complete() {
-	WriteGif(gv.name, gv.g)
+// AddFrame adds a frame to the GIF. Each frame is written once the next
+// one arrives, so repeated frames can be merged and the final frame held.
+func (gv *GifVisualizer) AddFrame(arr []int) error {
+	if err := gv.ready(); err != nil {
+		return err
+	}
+	frame := buildImage(*gv.cfg, gv.GifOptions, arr, &gv.hl)
+	gv.hl.next()
+	return gv.addImage(frame)
 }
 
-// buildImage creates an image from the array state
-func buildImage(arr []int) *image.Paletted {
+// addImage adds a rendered frame to the GIF
+func (gv *GifVisualizer) addImage(frame *image.Paletted) error {
+	delay := frameDelay(gv.cfg.Fps, gv.frames)
+	gv.frames++
+	if gv.pending != nil && bytes.Equal(gv.pending.Pix, frame.Pix) {
+		gv.delay += delay
+		return nil
+	}
+	gv.flush()
+	gv.pending = frame
+	gv.delay = delay
+	return gv.err
+}
+
+// flush encodes the pending frame as the difference to the canvas shown
+// before it
+func (gv *GifVisualizer) flush() {
+	if gv.pending == nil || gv.err != nil {
+		return
+	}
+	if gv.enc == nil {
+		b := gv.pending.Bounds()
+		p := append(gifPalette[:len(gifPalette):len(gifPalette)], color.Transparent)
+		if gv.Origin != nil {
+			p = originPalette
+		}
+		gv.enc = newGifWriter(gv.out, b.Dx(), b.Dy(), p, gv.LoopCount)
+		if gv.cfg.Seed != 0 {
+			gv.enc.writeComment(fmt.Sprintf("%s seed=%d", gv.name, gv.cfg.Seed))
+		}
+	}
+	if gv.canvas == nil || gv.canvas.Rect != gv.pending.Rect {
+		gv.enc.writeFrame(gv.pending, gv.delay, disposalNone, -1)
+	} else {
+		gv.enc.writeFrame(frameDelta(gv.canvas, gv.pending), gv.delay, disposalNone, gifTransparent)
+	}
+	gv.canvas = gv.pending
+	if gv.enc.err != nil {
+		gv.err = fmt.Errorf("gsv: writing %s: %w", gv.name, gv.enc.err)
+	}
+}
+
+// errNoSetup is returned by a GifVisualizer used before Setup
+var errNoSetup = errors.New("gsv: GifVisualizer used before Setup")
+
+// errCompleted is returned by a GifVisualizer given frames after Complete
+var errCompleted = errors.New("gsv: GifVisualizer used after Complete")
+
+// ready returns the error that keeps the GIF from taking frames, if any
+func (gv *GifVisualizer) ready() error {
+	switch {
+	case gv.err != nil:
+		return gv.err
+	case gv.done:
+		return errCompleted
+	case gv.out == nil:
+		return errNoSetup
+	}
+	return nil
+}
+
+// Complete writes the final frame and closes the GIF file. Later calls
+// until the next Setup only return the result of the first.
+func (gv *GifVisualizer) Complete() error {
+	if gv.done {
+		return gv.err
+	}
+	if gv.out == nil && gv.err == nil {
+		return errNoSetup
+	}
+	if gv.pending == nil {
+		gv.AddFrame(nil)
+	}
+	gv.delay += int(gv.Hold / (10 * time.Millisecond))
+	gv.flush()
+	if gv.enc != nil && gv.err == nil {
+		if err := gv.enc.close(); err != nil {
+			gv.err = fmt.Errorf("gsv: writing %s: %w", gv.name, err)
+		}
+	}
+	if gv.closer != nil {
+		if err := gv.closer.Close(); err != nil && gv.err == nil {
+			gv.err = fmt.Errorf("gsv: %w", err)
+		}
+		gv.closer = nil
+	}
+	gv.done = true
+	gv.out, gv.enc, gv.canvas, gv.pending = nil, nil, nil, nil
+	return gv.err
+}
+
+// frameDelay returns the GIF delay of frame k in 1/100 s. Delays are
+// rounded so the animation as a whole keeps the frame rate. GIF viewers
+// do not show frames faster than 50 fps, so higher rates are capped.
+func frameDelay(fps, k int) int {
+	if fps <= 0 || fps > 50 {
+		return 2
+	}
+	return (200*(k+1)+fps)/(2*fps) - (200*k+fps)/(2*fps)
+}
+
+// gifPalette holds the background followed by one colour per role:
+// plain bars in black, sorted ones in grey, the pivot in blue, compared
+// bars in green and swapped or written ones in red
+var gifPalette = color.Palette{
+	color.Gray{uint8(255)},
+	color.Gray{uint8(0)},
+	color.Gray{uint8(170)},
+	color.RGBA{38, 110, 210, 255},
+	color.RGBA{40, 160, 60, 255},
+	color.RGBA{220, 50, 47, 255},
+}
+
+// gifTransparent is the colour index of unchanged pixels in delta frames
+var gifTransparent = len(gifPalette)
+
+// originPalette is the palette of GIFs with GifOptions.Origin. A ramp
+// from the first to the last original index follows the transparent
+// colour.
+var originPalette = func() color.Palette {
+	p := append(gifPalette[:len(gifPalette):len(gifPalette)], color.Transparent)
+	for i := 0; i < originColors; i++ {
+		p = append(p, Viridis(float64(i)/float64(originColors-1)))
+	}
+	return p
+}()
+
+// originColors is the number of colours of the ramp in originPalette
+const originColors = 32
+
+// originColor returns the colour index of a bar that was at index pos of
+// n values before sorting
+func originColor(pos, n int) uint8 {
+	k := 0
+	if n > 1 {
+		k = pos * (originColors - 1) / (n - 1)
+	}
+	if k < 0 {
+		k = 0
+	} else if k >= originColors {
+		k = originColors - 1
+	}
+	return uint8(gifTransparent + 1 + k)
+}
+
+// frameDelta returns the smallest rectangle of next that differs from
+// prev. Pixels inside it that did not change are transparent, which
+// compresses better than repeating them.
+func frameDelta(prev, next *image.Paletted) *image.Paletted {
+	r := image.Rectangle{}
+	b := next.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		p := prev.Pix[prev.PixOffset(b.Min.X, y):prev.PixOffset(b.Max.X, y)]
+		n := next.Pix[next.PixOffset(b.Min.X, y):next.PixOffset(b.Max.X, y)]
+		first, last := -1, -1
+		for x := range n {
+			if p[x] != n[x] {
+				if first < 0 {
+					first = x
+				}
+				last = x
+			}
+		}
+		if first >= 0 {
+			r = r.Union(image.Rect(b.Min.X+first, y, b.Min.X+last+1, y+1))
+		}
+	}
+	if r.Empty() {
+		r = image.Rect(b.Min.X, b.Min.Y, b.Min.X+1, b.Min.Y+1)
+	}
+
+	delta := image.NewPaletted(r, next.Palette)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := next.Pix[next.PixOffset(x, y)]
+			if c == prev.Pix[prev.PixOffset(x, y)] {
+				c = uint8(gifTransparent)
+			}
+			delta.Pix[delta.PixOffset(x, y)] = c
+		}
+	}
+	return delta
+}
+
+// buildImage creates an image from the array state. Bars are coloured by
+// their role in h, which may be nil, or by opts.Origin.
+func buildImage(cfg Config, opts GifOptions, arr []int, h *highlight) *image.Paletted {
+	l := opts.layout(len(arr), cfg.Max)
+	palette := gifPalette
+	if opts.Origin != nil {
+		palette = originPalette
+	}
 	var frame = image.NewPaletted(
 		image.Rectangle{
 			image.Point{0, 0},
-			image.Point{len(arr), Max},
-		},
-		color.Palette{
-			color.Gray{uint8(255)},
-			color.Gray{uint8(0)},
+			image.Point{l.width, l.height},
 		},
+		palette,
 	)
+	if opts.Axis {
+		drawAxis(frame, l, cfg.Max)
+	}
 	for k, v := range arr {
-		frame.SetColorIndex(k, Max-v, uint8(1))
-		if Mode == 2 {
-			for y := Max - v + 1; y < Max; y++ {
-				frame.SetColorIndex(k, y, uint8(1))
-			}
+		r := rolePlain
+		if h != nil {
+			r = h.role(k)
 		}
+		c := 1 + uint8(r)
+		if k < len(opts.Origin) && (r == rolePlain || r == roleSorted) {
+			c = originColor(opts.Origin[k], len(arr))
+		}
+		if v < 1 {
+			continue
+		}
+		if v > cfg.Max {
+			v = cfg.Max
+		}
+		bottom := v
+		if cfg.Mode >= ModeBars {
+			bottom = 1
+		}
+		x := l.left + k*(l.barWidth+l.gap)
+		y0 := l.top + (cfg.Max-v)*l.valueHeight
+		y1 := l.top + (cfg.Max-bottom+1)*l.valueHeight
+		fillRect(frame, image.Rect(x, y0, x+l.barWidth, y1), c)
 	}
 	return frame
 }
 
+// drawAxis draws the value axis with a tick every few value steps and
+// the baseline below the bars
+func drawAxis(frame *image.Paletted, l layout, max int) {
+	x := l.left - axisSpace/2
+	bottom := l.top + max*l.valueHeight
+	fillRect(frame, image.Rect(x, l.top, x+1, bottom+1), 1)
+	fillRect(frame, image.Rect(x, bottom, l.right, bottom+1), 1)
+
+	step := 1
+	for _, s := range []int{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000} {
+		step = s
+		if s*l.valueHeight >= 4 {
+			break
+		}
+	}
+	for v := step; v <= max; v += step {
+		y := l.top + (max-v)*l.valueHeight
+		fillRect(frame, image.Rect(x-axisSpace/2, y, x, y+1), 1)
+	}
+}
+
+// fillRect sets all pixels of r inside the frame to colour index c
+func fillRect(frame *image.Paletted, r image.Rectangle, c uint8) {
+	r = r.Intersect(frame.Rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := frame.Pix[frame.PixOffset(r.Min.X, y):frame.PixOffset(r.Max.X, y)]
+		for i := range row {
+			row[i] = c
+		}
+	}
+}
+
 // WriteGif writes the GIF file to disk
-func WriteGif(name string, g *gif.GIF) {
+func WriteGif(name string, g *gif.GIF) error {
 	w, err := os.Create(name + ".gif")
 	if err != nil {
-		fmt.Println("os.Create")
-		panic(err)
+		return fmt.Errorf("gsv: %w", err)
 	}
-	defer func() {
-		if err := w.Close(); err != nil {
-			fmt.Println("w.Close")
-			panic(err)
-		}
-	}()
 	err = gif.EncodeAll(w, g)
+	if cerr := w.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
 	if err != nil {
-		fmt.Println("gif.EncodeAll")
-		panic(err)
+		return fmt.Errorf("gsv: writing %s: %w", name, err)
 	}
+	return nil
 }
 
 // WriteStdout writes the array to stdout as an ASCII visualization
+// using DefaultConfig
 func WriteStdout(arr []int) {
+	DefaultConfig().WriteStdout(arr)
+}
+
+// WriteStdout writes the array to stdout as an ASCII visualization.
+// ModeDots and ModeBars keep the drawing of earlier versions, with rows
+// counting values from the top: ModeDots fills each column from its value
+// down, ModeBars from the top to its value. StdoutVisualizer draws runs
+// without clearing the whole screen, as the other modes are drawn here.
+func (cfg Config) WriteStdout(arr []int) {
 	var buffer bytes.Buffer
+	if cfg.Mode == ModeDots || cfg.Mode == ModeBars {
+		writeLegacyText(&buffer, cfg, arr)
+	} else {
+		drawText(cfg, arr, nil).writeTo(&buffer)
+	}
 
-	for y := 0; y < Max; y++ {
+	if !cfg.Quiet {
+		time.Sleep(time.Second / time.Duration(cfg.Fps))
+		fmt.Print("\033[2J")
+		fmt.Print(buffer.String())
+	}
+}
+
+// writeLegacyText draws arr as Config.WriteStdout did before
+// StdoutVisualizer, one row per value from 0 to Max-1
+func writeLegacyText(buffer *bytes.Buffer, cfg Config, arr []int) {
+	for y := 0; y < cfg.Max; y++ {
 		for x := 0; x < len(arr); x++ {
-			if arr[x] == y || (arr[x] < y && Mode == 1) || (arr[x] > y && Mode == 2) {
+			if arr[x] == y || (arr[x] < y && cfg.Mode == ModeDots) || (arr[x] > y && cfg.Mode == ModeBars) {
 				buffer.WriteByte('#')
 			} else {
 				buffer.WriteByte(' ')
@@ -125,28 +599,26 @@ func WriteStdout(arr []int) {
 		}
 		buffer.WriteByte('\n')
 	}
-
-	if !test {
-		time.Sleep(time.Second / time.Duration(Fps))
-		fmt.Print("\033[2J")
-		fmt.Print(buffer.String())
-	}
 }
 
-// shuffle randomizes the order of elements in the array
-func shuffle(arr []int) []int {
-	for i := len(arr) - 1; i > 0; i-- {
-		if j := rand.Intn(i + 1); i != j {
-			arr[i], arr[j] = arr[j], arr[i]
+// shuffle randomizes the order of the elements, drawing from st.rng or
+// from the global source if it is nil
+func (st *sorter[T]) shuffle() {
+	intn := rand.Intn
+	if st.rng != nil {
+		intn = st.rng.Intn
+	}
+	for i := len(st.s) - 1; i > 0; i-- {
+		if j := intn(i + 1); i != j {
+			st.swap(i, j)
 		}
 	}
-	return arr
 }
 
-// isSorted checks if the array is sorted
-func isSorted(arr []int) bool {
-	for i := 0; i < len(arr)-1; i++ {
-		if arr[i] > arr[i+1] {
+// isSorted checks if the elements are sorted
+func (st *sorter[T]) isSorted() bool {
+	for i := 0; i < len(st.s)-1; i++ {
+		if st.greater(i, i+1) {
 			return false
 		}
 	}
@@ -157,48 +629,102 @@ func isSorted(arr []int) bool {
 
 // BogoSort is an implementation of https://en.wikipedia.org/wiki/Bogosort
 func BogoSort(arr []int, frameGen FrameGen) {
-	if frameGen != nil {
-		frameGen(arr)
+	BogoSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// BogoSortTraced is BogoSort reporting each operation to t
+func BogoSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).bogo()
+}
+
+// BogoSortRand returns BogoSortTraced shuffling with rng, so that runs
+// from the same seed are reproducible
+func BogoSortRand(rng *rand.Rand) TraceSorter {
+	return func(arr []int, t Tracer) {
+		st := intSorter(arr, t)
+		st.rng = rng
+		st.bogo()
 	}
-	for !isSorted(arr) {
-		arr = shuffle(arr)
-		if frameGen != nil {
-			frameGen(arr)
-		}
+}
+
+// BogoSortFunc sorts s in ascending order as determined by cmp with BogoSort
+func BogoSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).bogo()
+}
+
+// BogoSortOrdered sorts s in ascending order with BogoSort
+func BogoSortOrdered[T cmp.Ordered](s []T) {
+	BogoSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) bogo() {
+	for !st.isSorted() {
+		st.shuffle()
 	}
 }
 
 // BubbleSort is an implementation of https://en.wikipedia.org/wiki/Bubble_sort
 func BubbleSort(arr []int, frameGen FrameGen) {
-	for i := 0; i < len(arr); i++ {
-		for j := 0; j < len(arr)-1; j++ {
-			if arr[j] > arr[j+1] {
-				arr[j], arr[j+1] = arr[j+1], arr[j]
-				if frameGen != nil {
-					frameGen(arr)
-				}
+	BubbleSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// BubbleSortTraced is BubbleSort reporting each operation to t
+func BubbleSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).bubble()
+}
+
+// BubbleSortFunc sorts s in ascending order as determined by cmp with BubbleSort
+func BubbleSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).bubble()
+}
+
+// BubbleSortOrdered sorts s in ascending order with BubbleSort
+func BubbleSortOrdered[T cmp.Ordered](s []T) {
+	BubbleSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) bubble() {
+	n := len(st.s)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n-1; j++ {
+			if st.greater(j, j+1) {
+				st.swap(j, j+1)
 			}
 		}
+		st.t.Mark(n-1-i, MarkSorted)
 	}
 }
 
 // CocktailSort is an implementation of https://en.wikipedia.org/wiki/Cocktail_shaker_sort
 func CocktailSort(arr []int, frameGen FrameGen) {
-	for !isSorted(arr) {
-		for i := 0; i < len(arr)-2; i++ {
-			if arr[i] > arr[i+1] {
-				arr[i], arr[i+1] = arr[i+1], arr[i]
-				if frameGen != nil {
-					frameGen(arr)
-				}
+	CocktailSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// CocktailSortTraced is CocktailSort reporting each operation to t
+func CocktailSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).cocktail()
+}
+
+// CocktailSortFunc sorts s in ascending order as determined by cmp with CocktailSort
+func CocktailSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).cocktail()
+}
+
+// CocktailSortOrdered sorts s in ascending order with CocktailSort
+func CocktailSortOrdered[T cmp.Ordered](s []T) {
+	CocktailSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) cocktail() {
+	for !st.isSorted() {
+		for i := 0; i < len(st.s)-1; i++ {
+			if st.greater(i, i+1) {
+				st.swap(i, i+1)
 			}
 		}
-		for i := len(arr) - 2; i > 0; i-- {
-			if arr[i] > arr[i+1] {
-				arr[i], arr[i+1] = arr[i+1], arr[i]
-				if frameGen != nil {
-					frameGen(arr)
-				}
+		for i := len(st.s) - 2; i > 0; i-- {
+			if st.greater(i, i+1) {
+				st.swap(i, i+1)
 			}
 		}
 	}
@@ -206,7 +732,26 @@ func CocktailSort(arr []int, frameGen FrameGen) {
 
 // CombSort is an implementation of https://en.wikipedia.org/wiki/Comb_sort
 func CombSort(arr []int, frameGen FrameGen) {
-	gap := len(arr)
+	CombSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// CombSortTraced is CombSort reporting each operation to t
+func CombSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).comb()
+}
+
+// CombSortFunc sorts s in ascending order as determined by cmp with CombSort
+func CombSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).comb()
+}
+
+// CombSortOrdered sorts s in ascending order with CombSort
+func CombSortOrdered[T cmp.Ordered](s []T) {
+	CombSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) comb() {
+	gap := len(st.s)
 	swapped := true
 
 	for gap > 1 || swapped {
@@ -214,295 +759,555 @@ func CombSort(arr []int, frameGen FrameGen) {
 		if gap > 1 {
 			gap = int(float64(gap) / 1.3)
 		}
-		for i := 0; i < len(arr)-gap; i++ {
-			if arr[i] > arr[i+gap] {
-				arr[i], arr[i+gap] = arr[i+gap], arr[i]
+		for i := 0; i < len(st.s)-gap; i++ {
+			if st.greater(i, i+gap) {
+				st.swap(i, i+gap)
 				swapped = true
-				if frameGen != nil {
-					frameGen(arr)
-				}
 			}
 		}
 	}
 }
 
 // CountingSort is an implementation of https://en.wikipedia.org/wiki/Counting_sort
+// The counting range is taken from the highest value in arr.
 func CountingSort(arr []int, frameGen FrameGen) {
-	count := make([]int, Max+1)
+	CountingSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// CountingSortTraced is CountingSort reporting each operation to t
+func CountingSortTraced(arr []int, t Tracer) {
+	t = tracer(t)
+	if len(arr) == 0 {
+		return
+	}
+	count := make([]int, getMax(arr)+1)
 	for _, x := range arr {
 		count[x]++
+		t.AuxWrite(x, count[x])
 	}
 	z := 0
 	for i, c := range count {
 		for c > 0 {
 			arr[z] = i
+			t.Write(z, i)
 			z++
 			c--
 		}
-		if frameGen != nil {
-			frameGen(arr)
+	}
+}
+
+// CountingSortKey sorts s in ascending order of the non-negative keys key
+// returns for its elements. Elements with equal keys keep their order.
+func CountingSortKey[T any](s []T, key func(T) int) {
+	newSorter(s, nil, key, nil).counting()
+}
+
+func (st *sorter[T]) counting() {
+	if len(st.s) == 0 {
+		return
+	}
+	count := make([]int, st.maxKey()+1)
+	for _, v := range st.s {
+		count[st.key(v)]++
+	}
+	for i := 1; i < len(count); i++ {
+		count[i] += count[i-1]
+	}
+	output := make([]T, len(st.s))
+	for i := len(st.s) - 1; i >= 0; i-- {
+		k := st.key(st.s[i])
+		count[k]--
+		st.setAux(output, count[k], st.s[i])
+	}
+	for i, v := range output {
+		st.set(i, v)
+	}
+}
+
+// maxKey returns the highest key of the elements
+func (st *sorter[T]) maxKey() int {
+	max := st.key(st.s[0])
+	for _, v := range st.s {
+		if k := st.key(v); k > max {
+			max = k
 		}
 	}
+	return max
 }
 
 // CycleSort is an implementation of https://en.wikipedia.org/wiki/Cycle_sort
 func CycleSort(arr []int, frameGen FrameGen) {
-	for cycleStart := 0; cycleStart < len(arr)-1; cycleStart++ {
-		item := arr[cycleStart]
-		pos := cycleStart
-		for i := cycleStart + 1; i < len(arr); i++ {
-			if arr[i] < item {
-				pos++
-			}
-		}
+	CycleSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// CycleSortTraced is CycleSort reporting each operation to t
+func CycleSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).cycle()
+}
+
+// CycleSortFunc sorts s in ascending order as determined by cmp with CycleSort
+func CycleSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).cycle()
+}
+
+// CycleSortOrdered sorts s in ascending order with CycleSort
+func CycleSortOrdered[T cmp.Ordered](s []T) {
+	CycleSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) cycle() {
+	s, t := st.s, st.t
+	for cycleStart := 0; cycleStart < len(s)-1; cycleStart++ {
+		item := s[cycleStart]
+		pos := st.position(cycleStart, item)
 		if pos == cycleStart {
+			t.Mark(cycleStart, MarkSorted)
 			continue
 		}
-		for item == arr[pos] {
-			pos++
-		}
-		arr[pos], item = item, arr[pos]
+		pos = st.skipEqual(pos, item)
+		item = st.place(pos, item)
 		for pos != cycleStart {
-			pos = cycleStart
-			for i := cycleStart + 1; i < len(arr); i++ {
-				if arr[i] < item {
-					pos++
-				}
-			}
-			for item == arr[pos] {
-				pos++
-			}
-			arr[pos], item = item, arr[pos]
+			pos = st.skipEqual(st.position(cycleStart, item), item)
+			item = st.place(pos, item)
 		}
-		if frameGen != nil {
-			frameGen(arr)
+	}
+}
+
+// position returns the index item belongs to in the cycle starting at
+// cycleStart, not counting equal elements
+func (st *sorter[T]) position(cycleStart int, item T) int {
+	pos := cycleStart
+	for i := cycleStart + 1; i < len(st.s); i++ {
+		st.t.Compare(i, -1)
+		if st.cmp(st.s[i], item) < 0 {
+			pos++
 		}
 	}
+	return pos
+}
+
+// skipEqual moves pos past the elements equal to item
+func (st *sorter[T]) skipEqual(pos int, item T) int {
+	for st.cmp(item, st.s[pos]) == 0 {
+		st.t.Compare(pos, -1)
+		pos++
+	}
+	return pos
+}
+
+// place writes item to pos, marks it as sorted and returns the element
+// it replaced
+func (st *sorter[T]) place(pos int, item T) T {
+	prev := st.s[pos]
+	st.set(pos, item)
+	st.t.Mark(pos, MarkSorted)
+	return prev
 }
 
 // GnomeSort is an implementation of https://en.wikipedia.org/wiki/Gnome_sort
 func GnomeSort(arr []int, frameGen FrameGen) {
+	GnomeSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// GnomeSortTraced is GnomeSort reporting each operation to t
+func GnomeSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).gnome()
+}
+
+// GnomeSortFunc sorts s in ascending order as determined by cmp with GnomeSort
+func GnomeSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).gnome()
+}
+
+// GnomeSortOrdered sorts s in ascending order with GnomeSort
+func GnomeSortOrdered[T cmp.Ordered](s []T) {
+	GnomeSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) gnome() {
 	i := 0
-	for i < len(arr) {
-		if i == 0 || arr[i] >= arr[i-1] {
+	for i < len(st.s) {
+		if i == 0 || !st.less(i, i-1) {
 			i++
 		} else {
-			arr[i], arr[i-1] = arr[i-1], arr[i]
+			st.swap(i, i-1)
 			i--
 		}
-		if frameGen != nil {
-			frameGen(arr)
-		}
 	}
 }
 
 // InsertionSort is an implementation of https://en.wikipedia.org/wiki/Insertion_sort
 func InsertionSort(arr []int, frameGen FrameGen) {
-	for i := 1; i < len(arr); i++ {
-		key := arr[i]
+	InsertionSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// InsertionSortTraced is InsertionSort reporting each operation to t
+func InsertionSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).insertion()
+}
+
+// InsertionSortFunc sorts s in ascending order as determined by cmp with InsertionSort
+func InsertionSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).insertion()
+}
+
+// InsertionSortOrdered sorts s in ascending order with InsertionSort
+func InsertionSortOrdered[T cmp.Ordered](s []T) {
+	InsertionSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) insertion() {
+	s := st.s
+	for i := 1; i < len(s); i++ {
+		key := s[i]
 		j := i - 1
-		for j >= 0 && arr[j] > key {
-			arr[j+1] = arr[j]
+		for j >= 0 {
+			st.t.Compare(j, -1)
+			if st.cmp(s[j], key) <= 0 {
+				break
+			}
+			st.set(j+1, s[j])
 			j--
 		}
-		arr[j+1] = key
-		if frameGen != nil {
-			frameGen(arr)
+		if j+1 != i {
+			st.set(j+1, key)
 		}
 	}
 }
 
 // OddEvenSort is an implementation of https://en.wikipedia.org/wiki/Odd–even_sort
 func OddEvenSort(arr []int, frameGen FrameGen) {
+	OddEvenSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// OddEvenSortTraced is OddEvenSort reporting each operation to t
+func OddEvenSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).oddEven()
+}
+
+// OddEvenSortFunc sorts s in ascending order as determined by cmp with OddEvenSort
+func OddEvenSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).oddEven()
+}
+
+// OddEvenSortOrdered sorts s in ascending order with OddEvenSort
+func OddEvenSortOrdered[T cmp.Ordered](s []T) {
+	OddEvenSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) oddEven() {
 	sorted := false
 	for !sorted {
 		sorted = true
-		for i := 1; i < len(arr)-1; i += 2 {
-			if arr[i] > arr[i+1] {
-				arr[i], arr[i+1] = arr[i+1], arr[i]
+		for i := 1; i < len(st.s)-1; i += 2 {
+			if st.greater(i, i+1) {
+				st.swap(i, i+1)
 				sorted = false
 			}
-			if frameGen != nil {
-				frameGen(arr)
-			}
 		}
-		for i := 0; i < len(arr)-1; i += 2 {
-			if arr[i] > arr[i+1] {
-				arr[i], arr[i+1] = arr[i+1], arr[i]
+		for i := 0; i < len(st.s)-1; i += 2 {
+			if st.greater(i, i+1) {
+				st.swap(i, i+1)
 				sorted = false
 			}
-			if frameGen != nil {
-				frameGen(arr)
-			}
 		}
 	}
 }
 
 // SelectionSort is an implementation of https://en.wikipedia.org/wiki/Selection_sort
 func SelectionSort(arr []int, frameGen FrameGen) {
-	for i := 0; i < len(arr); i++ {
+	SelectionSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// SelectionSortTraced is SelectionSort reporting each operation to t
+func SelectionSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).selection()
+}
+
+// SelectionSortFunc sorts s in ascending order as determined by cmp with SelectionSort
+func SelectionSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).selection()
+}
+
+// SelectionSortOrdered sorts s in ascending order with SelectionSort
+func SelectionSortOrdered[T cmp.Ordered](s []T) {
+	SelectionSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) selection() {
+	for i := 0; i < len(st.s); i++ {
 		minIndex := i
-		for j := i + 1; j < len(arr); j++ {
-			if arr[j] < arr[minIndex] {
+		for j := i + 1; j < len(st.s); j++ {
+			if st.less(j, minIndex) {
 				minIndex = j
 			}
 		}
-		arr[i], arr[minIndex] = arr[minIndex], arr[i]
-		if frameGen != nil {
-			frameGen(arr)
+		if minIndex != i {
+			st.swap(i, minIndex)
 		}
+		st.t.Mark(i, MarkSorted)
 	}
 }
 
 // SleepSort is a non-standard sorting algorithm
 func SleepSort(arr []int, frameGen FrameGen) {
-	arr2 := make([]int, len(arr))
-	channel := make(chan int, 1)
-	for i := 0; i < len(arr); i++ {
-		go func(i int) {
-			time.Sleep(time.Duration(arr[i]) * time.Millisecond)
-			channel <- arr[i]
-		}(i)
+	SleepSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// SleepSortTraced is SleepSort reporting each operation to t
+func SleepSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).sleep()
+}
+
+// SleepSortKey sorts s in ascending order of the keys key returns for its
+// elements, sleeping a millisecond per unit of each key
+func SleepSortKey[T any](s []T, key func(T) int) {
+	newSorter(s, nil, key, nil).sleep()
+}
+
+// sleep wakes each element a millisecond per unit of its key after a
+// common start, so the time taken to start the goroutines does not
+// reorder them
+func (st *sorter[T]) sleep() {
+	channel := make(chan T, len(st.s))
+	start := time.Now()
+	for i := 0; i < len(st.s); i++ {
+		go func(v T) {
+			time.Sleep(time.Until(start.Add(time.Duration(st.key(v)) * time.Millisecond)))
+			channel <- v
+		}(st.s[i])
 	}
 
-	for i := 0; i < len(arr); i++ {
-		arr2[i] = <-channel
-		if frameGen != nil {
-			frameGen(arr2)
-		}
+	for i := 0; i < len(st.s); i++ {
+		st.set(i, <-channel)
 	}
-	copy(arr, arr2)
 }
 
 // StoogeSort is an implementation of https://en.wikipedia.org/wiki/Stooge_sort
 func StoogeSort(arr []int, frameGen FrameGen) {
-	stoogesort(arr, 0, len(arr)-1, frameGen)
+	StoogeSortTraced(arr, FrameTracer(arr, frameGen))
 }
 
-func stoogesort(arr []int, l, h int, frameGen FrameGen) {
-	if arr[l] > arr[h] {
-		arr[l], arr[h] = arr[h], arr[l]
-		if frameGen != nil {
-			frameGen(arr)
-		}
+// StoogeSortTraced is StoogeSort reporting each operation to t
+func StoogeSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).stooge(0, len(arr)-1)
+}
+
+// StoogeSortFunc sorts s in ascending order as determined by cmp with StoogeSort
+func StoogeSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).stooge(0, len(s)-1)
+}
+
+// StoogeSortOrdered sorts s in ascending order with StoogeSort
+func StoogeSortOrdered[T cmp.Ordered](s []T) {
+	StoogeSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) stooge(l, h int) {
+	enter(st.t)
+	defer leave(st.t)
+	if h < 0 {
+		return
+	}
+	if st.greater(l, h) {
+		st.swap(l, h)
 	}
 	if h-l+1 > 2 {
-		t := (h - l + 1) / 3
-		stoogesort(arr, l, h-t, frameGen)
-		stoogesort(arr, l+t, h, frameGen)
-		stoogesort(arr, l, h-t, frameGen)
+		n := (h - l + 1) / 3
+		st.stooge(l, h-n)
+		st.stooge(l+n, h)
+		st.stooge(l, h-n)
 	}
 }
 
 // PancakeSort is an implementation of https://en.wikipedia.org/wiki/Pancake_sorting
 func PancakeSort(arr []int, frameGen FrameGen) {
-	for uns := len(arr) - 1; uns > 0; uns-- {
+	PancakeSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// PancakeSortTraced is PancakeSort reporting each operation to t
+func PancakeSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).pancake()
+}
+
+// PancakeSortFunc sorts s in ascending order as determined by cmp with PancakeSort
+func PancakeSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).pancake()
+}
+
+// PancakeSortOrdered sorts s in ascending order with PancakeSort
+func PancakeSortOrdered[T cmp.Ordered](s []T) {
+	PancakeSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) pancake() {
+	for uns := len(st.s) - 1; uns > 0; uns-- {
 		maxIndex := 0
 		for i := 1; i <= uns; i++ {
-			if arr[i] > arr[maxIndex] {
+			if st.greater(i, maxIndex) {
 				maxIndex = i
 			}
 		}
-		pancakeFlip(arr, maxIndex, frameGen)
-		pancakeFlip(arr, uns, frameGen)
+		st.flip(maxIndex)
+		st.flip(uns)
+		st.t.Mark(uns, MarkSorted)
 	}
 }
 
-func pancakeFlip(arr []int, r int, frameGen FrameGen) {
+// flip reverses the first r+1 elements
+func (st *sorter[T]) flip(r int) {
 	for l := 0; l < r; l, r = l+1, r-1 {
-		arr[l], arr[r] = arr[r], arr[l]
-		if frameGen != nil {
-			frameGen(arr)
-		}
+		st.swap(l, r)
 	}
 }
 
 // QuickSort is an implementation of https://en.wikipedia.org/wiki/Quicksort
 func QuickSort(arr []int, frameGen FrameGen) {
-	quickSort(arr, 0, len(arr)-1, frameGen)
+	QuickSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// QuickSortTraced is QuickSort reporting each operation to t
+func QuickSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).quick(0, len(arr)-1)
+}
+
+// QuickSortFunc sorts s in ascending order as determined by cmp with QuickSort
+func QuickSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).quick(0, len(s)-1)
+}
+
+// QuickSortOrdered sorts s in ascending order with QuickSort
+func QuickSortOrdered[T cmp.Ordered](s []T) {
+	QuickSortFunc(s, cmp.Compare[T])
 }
 
-func quickSort(arr []int, l, r int, frameGen FrameGen) {
+func (st *sorter[T]) quick(l, r int) {
+	enter(st.t)
+	defer leave(st.t)
 	if l >= r {
+		if l == r {
+			st.t.Mark(l, MarkSorted)
+		}
 		return
 	}
-	pivot := partition(arr, l, r, frameGen)
-	quickSort(arr, l, pivot-1, frameGen)
-	quickSort(arr, pivot+1, r, frameGen)
+	pivot := st.partition(l, r)
+	st.quick(l, pivot-1)
+	st.quick(pivot+1, r)
 }
 
-func partition(arr []int, l, r int, frameGen FrameGen) int {
-	pivot := arr[r]
+func (st *sorter[T]) partition(l, r int) int {
+	st.t.Mark(r, MarkPivot)
 	i := l
 	for j := l; j < r; j++ {
-		if arr[j] <= pivot {
-			arr[i], arr[j] = arr[j], arr[i]
+		if !st.greater(j, r) {
+			if i != j {
+				st.swap(i, j)
+			}
 			i++
 		}
-		if frameGen != nil {
-			frameGen(arr)
-		}
 	}
-	arr[i], arr[r] = arr[r], arr[i]
-	if frameGen != nil {
-		frameGen(arr)
+	if i != r {
+		st.swap(i, r)
 	}
+	st.t.Mark(i, MarkSorted)
 	return i
 }
 
 // MergeSort is an implementation of https://en.wikipedia.org/wiki/Merge_sort
 func MergeSort(arr []int, frameGen FrameGen) {
-	mergesort(arr, frameGen)
+	MergeSortTraced(arr, FrameTracer(arr, frameGen))
 }
 
-func mergesort(arr []int, frameGen FrameGen) []int {
-	if len(arr) <= 1 {
-		return arr
+// MergeSortTraced is MergeSort reporting each operation to t
+func MergeSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).mergeSort()
+}
+
+// MergeSortFunc sorts s in ascending order as determined by cmp with MergeSort
+func MergeSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).mergeSort()
+}
+
+// MergeSortOrdered sorts s in ascending order with MergeSort
+func MergeSortOrdered[T cmp.Ordered](s []T) {
+	MergeSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) mergeSort() {
+	aux := make([]T, len(st.s))
+	st.mergesort(aux, 0, len(st.s))
+}
+
+// mergesort sorts s[lo:hi] using the same range of aux as scratch space
+func (st *sorter[T]) mergesort(aux []T, lo, hi int) {
+	enter(st.t)
+	defer leave(st.t)
+	if hi-lo <= 1 {
+		return
 	}
-	mid := len(arr) / 2
-	left := mergesort(arr[:mid], frameGen)
-	right := mergesort(arr[mid:], frameGen)
-	return merge(left, right, frameGen)
+	mid := (lo + hi) / 2
+	st.mergesort(aux, lo, mid)
+	st.mergesort(aux, mid, hi)
+	st.merge(aux, lo, mid, hi)
 }
 
-func merge(left, right []int, frameGen FrameGen) []int {
-	result := make([]int, 0, len(left)+len(right))
-	for len(left) > 0 && len(right) > 0 {
-		if left[0] <= right[0] {
-			result = append(result, left[0])
-			left = left[1:]
-		} else {
-			result = append(result, right[0])
-			right = right[1:]
+// merge combines the sorted runs s[lo:mid] and s[mid:hi]. Comparisons
+// are reported with the positions the elements held before merging.
+func (st *sorter[T]) merge(aux []T, lo, mid, hi int) {
+	for k := lo; k < hi; k++ {
+		st.setAux(aux, k, st.s[k])
+	}
+	i, j := lo, mid
+	for k := lo; k < hi; k++ {
+		if i < mid && j < hi {
+			st.t.Compare(i, j)
 		}
-		if frameGen != nil {
-			frameGen(result)
+		if j >= hi || (i < mid && st.cmp(aux[i], aux[j]) <= 0) {
+			st.set(k, aux[i])
+			i++
+		} else {
+			st.set(k, aux[j])
+			j++
 		}
 	}
-	result = append(result, left...)
-	result = append(result, right...)
-	if frameGen != nil {
-		frameGen(result)
-	}
-	return result
 }
 
 // ShellSort is an implementation of https://en.wikipedia.org/wiki/Shellsort
 func ShellSort(arr []int, frameGen FrameGen) {
-	n := len(arr)
+	ShellSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// ShellSortTraced is ShellSort reporting each operation to t
+func ShellSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).shell()
+}
+
+// ShellSortFunc sorts s in ascending order as determined by cmp with ShellSort
+func ShellSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).shell()
+}
+
+// ShellSortOrdered sorts s in ascending order with ShellSort
+func ShellSortOrdered[T cmp.Ordered](s []T) {
+	ShellSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) shell() {
+	s := st.s
+	n := len(s)
 	for gap := n / 2; gap > 0; gap /= 2 {
 		for i := gap; i < n; i++ {
-			temp := arr[i]
+			temp := s[i]
 			j := i
-			for ; j >= gap && arr[j-gap] > temp; j -= gap {
-				arr[j] = arr[j-gap]
-				if frameGen != nil {
-					frameGen(arr)
+			for ; j >= gap; j -= gap {
+				st.t.Compare(j-gap, -1)
+				if st.cmp(s[j-gap], temp) <= 0 {
+					break
 				}
+				st.set(j, s[j-gap])
 			}
-			arr[j] = temp
-			if frameGen != nil {
-				frameGen(arr)
+			if j != i {
+				st.set(j, temp)
 			}
 		}
 	}
@@ -510,43 +1315,71 @@ func ShellSort(arr []int, frameGen FrameGen) {
 
 // HeapSort is an implementation of https://en.wikipedia.org/wiki/Heapsort
 func HeapSort(arr []int, frameGen FrameGen) {
-	buildMaxHeap(arr, frameGen)
-	for i := len(arr) - 1; i > 0; i-- {
-		arr[0], arr[i] = arr[i], arr[0]
-		maxHeapify(arr, 0, i, frameGen)
-	}
+	HeapSortTraced(arr, FrameTracer(arr, frameGen))
 }
 
-func buildMaxHeap(arr []int, frameGen FrameGen) {
-	for i := len(arr)/2 - 1; i >= 0; i-- {
-		maxHeapify(arr, i, len(arr), frameGen)
+// HeapSortTraced is HeapSort reporting each operation to t
+func HeapSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).heap()
+}
+
+// HeapSortFunc sorts s in ascending order as determined by cmp with HeapSort
+func HeapSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).heap()
+}
+
+// HeapSortOrdered sorts s in ascending order with HeapSort
+func HeapSortOrdered[T cmp.Ordered](s []T) {
+	HeapSortFunc(s, cmp.Compare[T])
+}
+
+func (st *sorter[T]) heap() {
+	n := len(st.s)
+	for i := n/2 - 1; i >= 0; i-- {
+		st.maxHeapify(i, n)
+	}
+	for i := n - 1; i > 0; i-- {
+		st.swap(0, i)
+		st.t.Mark(i, MarkSorted)
+		st.maxHeapify(0, i)
+	}
+	if n > 0 {
+		st.t.Mark(0, MarkSorted)
 	}
 }
 
-func maxHeapify(arr []int, i, n int, frameGen FrameGen) {
+func (st *sorter[T]) maxHeapify(i, n int) {
+	enter(st.t)
+	defer leave(st.t)
 	largest := i
 	left := 2*i + 1
 	right := 2*i + 2
-	if left < n && arr[left] > arr[largest] {
+	if left < n && st.greater(left, largest) {
 		largest = left
 	}
-	if right < n && arr[right] > arr[largest] {
+	if right < n && st.greater(right, largest) {
 		largest = right
 	}
 	if largest != i {
-		arr[i], arr[largest] = arr[largest], arr[i]
-		if frameGen != nil {
-			frameGen(arr)
-		}
-		maxHeapify(arr, largest, n, frameGen)
+		st.swap(i, largest)
+		st.maxHeapify(largest, n)
 	}
 }
 
 // RadixSort is an implementation of https://en.wikipedia.org/wiki/Radix_sort
 func RadixSort(arr []int, frameGen FrameGen) {
+	RadixSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// RadixSortTraced is RadixSort reporting each operation to t
+func RadixSortTraced(arr []int, t Tracer) {
+	t = tracer(t)
+	if len(arr) == 0 {
+		return
+	}
 	maxValue := getMax(arr)
 	for exp := 1; maxValue/exp > 0; exp *= 10 {
-		countingSortByDigit(arr, exp, frameGen)
+		countingSortByDigit(arr, exp, t)
 	}
 }
 
@@ -560,7 +1393,7 @@ func getMax(arr []int) int {
 	return max
 }
 
-func countingSortByDigit(arr []int, exp int, frameGen FrameGen) {
+func countingSortByDigit(arr []int, exp int, t Tracer) {
 	output := make([]int, len(arr))
 	count := make([]int, 10)
 
@@ -576,41 +1409,97 @@ func countingSortByDigit(arr []int, exp int, frameGen FrameGen) {
 	for i := len(arr) - 1; i >= 0; i-- {
 		index := (arr[i] / exp) % 10
 		output[count[index]-1] = arr[i]
+		t.AuxWrite(count[index]-1, arr[i])
 		count[index]--
 	}
 
-	copy(arr, output)
-	if frameGen != nil {
-		frameGen(arr)
+	for i, v := range output {
+		if arr[i] != v {
+			arr[i] = v
+			t.Write(i, v)
+		}
+	}
+}
+
+// RadixSortKey sorts s in ascending order of the non-negative keys key
+// returns for its elements. Elements with equal keys keep their order.
+func RadixSortKey[T any](s []T, key func(T) int) {
+	newSorter(s, nil, key, nil).radix()
+}
+
+func (st *sorter[T]) radix() {
+	if len(st.s) == 0 {
+		return
+	}
+	maxKey := st.maxKey()
+	output := make([]T, len(st.s))
+	for exp := 1; maxKey/exp > 0; exp *= 10 {
+		var count [10]int
+		for _, v := range st.s {
+			count[st.key(v)/exp%10]++
+		}
+		for i := 1; i < 10; i++ {
+			count[i] += count[i-1]
+		}
+		for i := len(st.s) - 1; i >= 0; i-- {
+			d := st.key(st.s[i]) / exp % 10
+			count[d]--
+			st.setAux(output, count[d], st.s[i])
+		}
+		for i, v := range output {
+			st.set(i, v)
+		}
 	}
 }
 
 // BitonicSort is an implementation of https://en.wikipedia.org/wiki/Bitonic_sorter
 func BitonicSort(arr []int, frameGen FrameGen) {
-	bitonicSort(arr, 0, len(arr), 1, frameGen)
+	BitonicSortTraced(arr, FrameTracer(arr, frameGen))
+}
+
+// BitonicSortTraced is BitonicSort reporting each operation to t
+func BitonicSortTraced(arr []int, t Tracer) {
+	intSorter(arr, t).bitonicSort(0, len(arr), 1)
+}
+
+// BitonicSortFunc sorts s in ascending order as determined by cmp with BitonicSort
+func BitonicSortFunc[T any](s []T, cmp func(a, b T) int) {
+	newSorter(s, cmp, nil, nil).bitonicSort(0, len(s), 1)
 }
 
-func bitonicSort(arr []int, low, cnt, dir int, frameGen FrameGen) {
+// BitonicSortOrdered sorts s in ascending order with BitonicSort
+func BitonicSortOrdered[T cmp.Ordered](s []T) {
+	BitonicSortFunc(s, cmp.Compare[T])
+}
+
+// bitonicSort sorts cnt elements from low in ascending order for dir 1
+// and in descending order for dir 0. The first half is sorted against dir
+// so that the merge also works for counts that are not a power of two.
+func (st *sorter[T]) bitonicSort(low, cnt, dir int) {
+	enter(st.t)
+	defer leave(st.t)
 	if cnt > 1 {
 		k := cnt / 2
-		bitonicSort(arr, low, k, 1, frameGen)
-		bitonicSort(arr, low+k, k, 0, frameGen)
-		bitonicMerge(arr, low, cnt, dir, frameGen)
+		st.bitonicSort(low, k, 1-dir)
+		st.bitonicSort(low+k, cnt-k, dir)
+		st.bitonicMerge(low, cnt, dir)
 	}
 }
 
-func bitonicMerge(arr []int, low, cnt, dir int, frameGen FrameGen) {
+func (st *sorter[T]) bitonicMerge(low, cnt, dir int) {
+	enter(st.t)
+	defer leave(st.t)
 	if cnt > 1 {
-		k := cnt / 2
-		for i := low; i < low+k; i++ {
-			if (arr[i] > arr[i+k]) == (dir == 1) {
-				arr[i], arr[i+k] = arr[i+k], arr[i]
-				if frameGen != nil {
-					frameGen(arr)
-				}
+		k := 1
+		for k*2 < cnt {
+			k *= 2
+		}
+		for i := low; i < low+cnt-k; i++ {
+			if st.greater(i, i+k) == (dir == 1) {
+				st.swap(i, i+k)
 			}
 		}
-		bitonicMerge(arr, low, k, dir, frameGen)
-		bitonicMerge(arr, low+k, k, dir, frameGen)
+		st.bitonicMerge(low, k, dir)
+		st.bitonicMerge(low+k, cnt-k, dir)
 	}
 }
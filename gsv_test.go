@@ -1,140 +1,95 @@
 package gsv
 
 import (
-	cryptoRand "crypto/rand"
 	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
 )
 
 var visName string
 var sorterMap map[string]Sorter
+var tracedMap map[string]TraceSorter
 
 func init() {
-	test = true
-
-	sorterMap = map[string]Sorter{
-		"bogo":      BogoSort,
-		"bubble":    BubbleSort,
-		"cocktail":  CocktailSort,
-		"comb":      CombSort,
-		"counting":  CountingSort,
-		"cycle":     CycleSort,
-		"gnome":     GnomeSort,
-		"insertion": InsertionSort,
-		"oddEven":   OddEvenSort,
-		"selection": SelectionSort,
-		"sleep":     SleepSort,
-		"stooge":    StoogeSort,
-		"pancake":   PancakeSort,
-		"quick":     QuickSort,
-		"merge":     MergeSort,
-		"shell":     ShellSort,
-		"heap":      HeapSort,
-		"radix":     RadixSort,
-		"bitonic":   BitonicSort,
-	}
-}
-
-// StdoutVisualizer implements the Visualizer interface for stdout output
-type StdoutVisualizer struct{}
-
-func (sv *StdoutVisualizer) Setup(name string) {
-	// No setup required for stdout
-}
-
-func (sv *StdoutVisualizer) AddFrame(arr []int) {
-	WriteStdout(arr)
-}
-
-func (sv *StdoutVisualizer) Complete() {
-	// No completion step required for stdout
-}
-
-func randomArray(n int, max int) []int {
-	arr := make([]int, n)
-	for i := 0; i < n; i++ {
-		b := make([]byte, 1)
-		cryptoRand.Read(b)
-		number := float64(b[0])
-		arr[i] = int(number / 255 * float64(max))
+	sorterMap = map[string]Sorter{}
+	tracedMap = map[string]TraceSorter{}
+	for _, a := range Algorithms() {
+		sorterMap[a.Name] = a.Sort
+		tracedMap[a.Name] = a.Trace
 	}
-	return arr
 }
 
-func makeVisualizer(name string) Visualizer {
+func makeVisualizer(cfg Config, name string) Visualizer {
 	if name == "gif" {
-		return &GifVisualizer{}
+		return NewGifVisualizer(cfg)
 	}
 	if name == "stdout" {
-		return &StdoutVisualizer{}
+		return NewStdoutVisualizer(cfg)
 	}
 	return nil
 }
 
-func runSort(visName string, arr []int, algo string, sortFunc Sorter) {
-	visualizer := makeVisualizer(visName)
-	visualizer.Setup(algo)
+func runSort(t *testing.T, cfg Config, visName string, arr []int, algo string, sortFunc Sorter) {
+	visualizer := makeVisualizer(cfg, visName)
+	if err := visualizer.Setup(algo); err != nil {
+		t.Fatal(err)
+	}
 
-	sortFunc(arr, visualizer.AddFrame)
-	visualizer.Complete()
+	input := cloneArray(arr)
+	sortFunc(arr, func(arr []int) {
+		if err := visualizer.AddFrame(arr); err != nil {
+			t.Error(err)
+		}
+	})
+	if err := visualizer.Complete(); err != nil {
+		t.Error(err)
+	}
+	a, _ := Lookup(algo)
+	checker(a)(t, algo, input, arr)
 }
 
 func Test_GIF(t *testing.T) {
-	Max = 9
-	Count = 9
-	Mode = 2
+	cfg := Config{Max: 9, Count: 9, Mode: 2, Quiet: true}
 
-	runSort("gif", randomArray(Count, Max), "selection", SelectionSort)
+	runSort(t, cfg, "gif", gen.Random(cfg.Count, cfg.Max, nil), "selection", SelectionSort)
 
-	Mode = 1
+	cfg.Mode = 1
 
 	for k, v := range sorterMap {
 		t.Log(k)
-		runSort("gif", randomArray(Count, Max), k, v)
+		runSort(t, cfg, "gif", gen.Random(cfg.Count, cfg.Max, nil), k, v)
 	}
 
 	t.Log("finish")
 }
 
 func Test_STDOUT(t *testing.T) {
-	Max = 9
-	Count = 9
-	Mode = 1
+	cfg := Config{Max: 9, Count: 9, Mode: 1, Quiet: true}
 
 	for k, v := range sorterMap {
 		t.Log(k)
-		runSort("stdout", randomArray(Count, Max), k, v)
+		runSort(t, cfg, "stdout", gen.Random(cfg.Count, cfg.Max, nil), k, v)
 	}
 
 	t.Log("finish")
 }
 
 // go test -bench=.
-func Benchmark_bogo_sort(b *testing.B)      { benchmarkSort("bogo", b) }
-func Benchmark_bubble_sort(b *testing.B)    { benchmarkSort("bubble", b) }
-func Benchmark_cocktail_sort(b *testing.B)  { benchmarkSort("cocktail", b) }
-func Benchmark_comb_sort(b *testing.B)      { benchmarkSort("comb", b) }
-func Benchmark_counting_sort(b *testing.B)  { benchmarkSort("counting", b) }
-func Benchmark_cycle_sort(b *testing.B)     { benchmarkSort("cycle", b) }
-func Benchmark_gnome_sort(b *testing.B)     { benchmarkSort("gnome", b) }
-func Benchmark_insertion_sort(b *testing.B) { benchmarkSort("insertion", b) }
-func Benchmark_oddEven_sort(b *testing.B)   { benchmarkSort("oddEven", b) }
-func Benchmark_selection_sort(b *testing.B) { benchmarkSort("selection", b) }
-func Benchmark_sleep_sort(b *testing.B)     { benchmarkSort("sleep", b) }
-func Benchmark_stooge_sort(b *testing.B)    { benchmarkSort("stooge", b) }
-func Benchmark_pancake_sort(b *testing.B)   { benchmarkSort("pancake", b) }
-func Benchmark_quick_sort(b *testing.B)     { benchmarkSort("quick", b) }
-func Benchmark_shell_sort(b *testing.B)     { benchmarkSort("shell", b) }
-func Benchmark_heap_sort(b *testing.B)      { benchmarkSort("heap", b) }
-func Benchmark_merge_sort(b *testing.B)     { benchmarkSort("merge", b) }
-func Benchmark_radix_sort(b *testing.B)     { benchmarkSort("radix", b) }
-func Benchmark_bitonic_sort(b *testing.B)   { benchmarkSort("bitonic", b) }
+func BenchmarkSort(b *testing.B) {
+	for _, a := range Algorithms() {
+		if a.Check(make([]int, Count)) != nil {
+			continue
+		}
+		b.Run(a.Name, func(b *testing.B) { benchmarkSort(a.Name, b) })
+	}
+}
 
 // WriteNop is a writer for FrameGen that does nothing.
 // Ensures we only benchmark algorithms.
 func WriteNop(_ []int) {}
 
 func benchmarkSort(sort string, b *testing.B) {
-	arr := randomArray(Count, Max)
+	arr := gen.Random(Count, Max, nil)
 	frameGen := FrameGen(WriteNop)
 	if sortFunc, found := sorterMap[sort]; found {
 		for n := 0; n < b.N; n++ {
@@ -150,6 +105,60 @@ func cloneArray(source []int) []int {
 	return destination
 }
 
+// TestConfigIsolation checks that two Configs render independently of each
+// other and of the package-level defaults.
+func TestConfigIsolation(t *testing.T) {
+	arr := []int{3, 1, 2}
+	small := buildImage(Config{Max: 3, Mode: 1}, GifOptions{}, arr, nil)
+	large := buildImage(Config{Max: 7, Mode: 2}, GifOptions{}, arr, nil)
+
+	if h := small.Bounds().Dy(); h != 3 {
+		t.Errorf("Expected height 3, got %d", h)
+	}
+	if h := large.Bounds().Dy(); h != 7 {
+		t.Errorf("Expected height 7, got %d", h)
+	}
+	if small.ColorIndexAt(1, 1) != 0 {
+		t.Error("Expected mode 1 to draw dots only")
+	}
+	if large.ColorIndexAt(1, 6) != 1 {
+		t.Error("Expected mode 2 to draw filled bars")
+	}
+}
+
+// TestGifScaling checks the size and placement of scaled bars.
+func TestGifScaling(t *testing.T) {
+	cfg := Config{Max: 4, Mode: 2}
+	opts := GifOptions{BarWidth: 3, ValueHeight: 2, Gap: 1, Margin: 2}
+	frame := buildImage(cfg, opts, []int{1, 4}, nil)
+
+	if b := frame.Bounds(); b.Dx() != 2+3+1+3+2 || b.Dy() != 2+4*2+2 {
+		t.Fatalf("Unexpected image size %v", b.Size())
+	}
+	for x := 0; x < frame.Bounds().Dx(); x++ {
+		bar := x >= 2 && x < 5 || x >= 6 && x < 9
+		if got := frame.ColorIndexAt(x, 9) == 1; got != bar {
+			t.Errorf("Expected bar at x=%d to be %v", x, bar)
+		}
+	}
+	if frame.ColorIndexAt(3, 7) != 0 || frame.ColorIndexAt(7, 2) != 1 {
+		t.Error("Expected bar heights to scale with ValueHeight")
+	}
+
+	opts = GifOptions{Width: 200, Height: 100, Margin: 5, Axis: true}
+	frame = buildImage(cfg, opts, []int{1, 2, 3, 4}, nil)
+	if b := frame.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Errorf("Expected a 200x100 image, got %v", b.Size())
+	}
+	l := opts.layout(4, cfg.Max)
+	if l.barWidth != (200-5-4-5)/4 || l.valueHeight != (100-10-2)/4 {
+		t.Errorf("Unexpected scaled bar size %dx%d", l.barWidth, l.valueHeight)
+	}
+	if frame.ColorIndexAt(l.left-axisSpace/2, l.top) != 1 {
+		t.Error("Expected the value axis to be drawn")
+	}
+}
+
 // TestCloneArray checks that cloneArray creates a separate copy and not a slice backed by the same array.
 func TestCloneArray(t *testing.T) {
 	s := []int{1, 2, 3, 4, 5}
@@ -171,12 +180,15 @@ func TestCloneArray(t *testing.T) {
 // BenchmarkConsistentArrayNoFramegen times the sort algorithms for the same array of random data
 // for each algorithm without the overhead of Frame generation.
 func BenchmarkConsistentArrayNoFramegen(b *testing.B) {
-	arr := randomArray(1000, 750)
-	for method, sortFn := range sorterMap {
-		b.Run(method, func(b *testing.B) {
+	arr := gen.Random(1000, 750, nil)
+	for _, a := range Algorithms() {
+		if a.Check(arr) != nil {
+			continue
+		}
+		b.Run(a.Name, func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				arrCopy := cloneArray(arr)
-				sortFn(arrCopy, nil)
+				a.Sort(arrCopy, nil)
 			}
 		})
 	}
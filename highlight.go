@@ -0,0 +1,99 @@
+package gsv
+
+// role describes how a bar is drawn
+type role uint8
+
+const (
+	rolePlain role = iota
+	roleSorted
+	rolePivot
+	roleCompare
+	roleSwap
+)
+
+// highlight tracks the roles of the indices touched by the operations
+// leading to the next frame
+type highlight struct {
+	touched map[int]bool
+	cmp     [2]int
+	pivot   int
+	sorted  map[int]bool
+}
+
+// reset forgets everything, e.g. for a new run
+func (h *highlight) reset() {
+	h.touched = map[int]bool{}
+	h.cmp = [2]int{-1, -1}
+	h.pivot = -1
+	h.sorted = map[int]bool{}
+}
+
+// add records e. Only the most recent comparison is kept.
+func (h *highlight) add(e Event) {
+	if h.touched == nil {
+		h.reset()
+	}
+	switch e.Op {
+	case OpCompare:
+		h.cmp = [2]int{e.I, e.J}
+	case OpSwap:
+		h.touched[e.I] = true
+		h.touched[e.J] = true
+	case OpWrite:
+		h.touched[e.I] = true
+	case OpMark:
+		switch MarkKind(e.J) {
+		case MarkPivot:
+			h.pivot = e.I
+		case MarkSorted:
+			h.sorted[e.I] = true
+			if h.pivot == e.I {
+				h.pivot = -1
+			}
+		}
+	}
+}
+
+// role returns how the bar at index k is drawn in the next frame
+func (h *highlight) role(k int) role {
+	switch {
+	case h.touched == nil:
+		return rolePlain
+	case h.touched[k]:
+		return roleSwap
+	case h.cmp[0] == k || h.cmp[1] == k:
+		return roleCompare
+	case h.pivot == k:
+		return rolePivot
+	case h.sorted[k]:
+		return roleSorted
+	}
+	return rolePlain
+}
+
+// clone returns a copy that does not share its maps with h
+func (h *highlight) clone() highlight {
+	c := *h
+	if h.touched != nil {
+		c.touched = make(map[int]bool, len(h.touched))
+		for k := range h.touched {
+			c.touched[k] = true
+		}
+		c.sorted = make(map[int]bool, len(h.sorted))
+		for k := range h.sorted {
+			c.sorted[k] = true
+		}
+	}
+	return c
+}
+
+// next clears the operations once a frame has been drawn
+func (h *highlight) next() {
+	if h.touched == nil {
+		return
+	}
+	if len(h.touched) > 0 {
+		h.touched = map[int]bool{}
+	}
+	h.cmp = [2]int{-1, -1}
+}
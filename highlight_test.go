@@ -0,0 +1,40 @@
+package gsv
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestHighlight checks that the bars touched by the last operations are
+// coloured by their role.
+func TestHighlight(t *testing.T) {
+	gv := NewGifVisualizer(Config{Max: 9, Mode: 2})
+	gv.Setup(filepath.Join(t.TempDir(), "highlight"))
+	defer gv.Complete()
+	arr := []int{5, 4, 3, 2}
+	tracer := VisualizerTracer(arr, gv)
+	tracer.Mark(3, MarkPivot)
+	tracer.Mark(0, MarkSorted)
+	tracer.Compare(1, 2)
+	arr[1], arr[2] = arr[2], arr[1]
+	tracer.Swap(1, 2)
+
+	frame := gv.pending
+	expected := []role{roleSorted, roleSwap, roleSwap, rolePivot}
+	for k, r := range expected {
+		if c := frame.ColorIndexAt(k, 8); c != uint8(r)+1 {
+			t.Errorf("Expected bar %d to have colour %d, got %d", k, r+1, c)
+		}
+	}
+
+	tracer.Compare(2, 3)
+	arr[0] = 1
+	tracer.Write(0, 1)
+	frame = gv.pending
+	expected = []role{roleSwap, rolePlain, roleCompare, roleCompare}
+	for k, r := range expected {
+		if c := frame.ColorIndexAt(k, 8); c != uint8(r)+1 {
+			t.Errorf("Expected bar %d to have colour %d, got %d", k, r+1, c)
+		}
+	}
+}
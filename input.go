@@ -0,0 +1,89 @@
+package gsv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseInput reads the values to sort from data, either as a JSON array
+// or as numbers separated by whitespace, commas or semicolons, so plain
+// lists and CSV files both work
+func ParseInput(data []byte) ([]int, error) {
+	data = bytes.TrimSpace(data)
+	arr := []int{}
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &arr); err != nil {
+			return nil, fmt.Errorf("gsv: input: %w", err)
+		}
+		return arr, nil
+	}
+	fields := strings.FieldsFunc(string(data), func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+	for k, field := range fields {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("gsv: input value %d: %q is not an integer", k+1, field)
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+// ReadInput reads the values to sort from r as ParseInput does
+func ReadInput(r io.Reader) ([]int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("gsv: %w", err)
+	}
+	return ParseInput(data)
+}
+
+// LoadInput reads the values to sort from the file at path as ParseInput
+// does
+func LoadInput(path string) ([]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	arr, err := ParseInput(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return arr, nil
+}
+
+// ForInput returns cfg adjusted to sort arr: Count is set to its length
+// and a Max of 0 is derived from its highest value. It fails if arr is
+// empty, holds negative values or values above a given Max.
+func (cfg Config) ForInput(arr []int) (Config, error) {
+	if len(arr) == 0 {
+		return cfg, errors.New("gsv: empty input")
+	}
+	highest := 0
+	for k, v := range arr {
+		if v < 0 {
+			return cfg, fmt.Errorf("gsv: input value %d is negative: %d", k+1, v)
+		}
+		if cfg.Max > 0 && v > cfg.Max {
+			return cfg, fmt.Errorf("gsv: input value %d exceeds the highest value %d: %d", k+1, cfg.Max, v)
+		}
+		if v > highest {
+			highest = v
+		}
+	}
+	cfg.Count = len(arr)
+	if cfg.Max <= 0 {
+		cfg.Max = highest
+		if cfg.Max == 0 {
+			cfg.Max = 1
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,74 @@
+package gsv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseInput checks the accepted input formats and the errors for
+// malformed ones.
+func TestParseInput(t *testing.T) {
+	tests := map[string][]int{
+		"3 1 2":             {3, 1, 2},
+		"3,1,2\n":           {3, 1, 2},
+		"3, 1;\r\n2\t10":    {3, 1, 2, 10},
+		" [3, 1, 2] ":       {3, 1, 2},
+		"":                  {},
+		"[]":                {},
+		"5\n4\n3\n2\n1\n\n": {5, 4, 3, 2, 1},
+	}
+	for data, expected := range tests {
+		got, err := ParseInput([]byte(data))
+		if err != nil {
+			t.Errorf("%q: %v", data, err)
+		} else if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%q: expected %v, got %v", data, expected, got)
+		}
+	}
+	for _, data := range []string{"1 two 3", "[1, 2", "1.5", `["1"]`} {
+		if _, err := ParseInput([]byte(data)); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+
+	arr, err := ReadInput(strings.NewReader("7 8"))
+	if err != nil || !reflect.DeepEqual(arr, []int{7, 8}) {
+		t.Errorf("ReadInput returned %v, %v", arr, err)
+	}
+	path := filepath.Join(t.TempDir(), "input.csv")
+	if err := os.WriteFile(path, []byte("4,2,9\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	arr, err = LoadInput(path)
+	if err != nil || !reflect.DeepEqual(arr, []int{4, 2, 9}) {
+		t.Errorf("LoadInput returned %v, %v", arr, err)
+	}
+	if _, err := LoadInput(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+// TestForInput checks that the Config is fitted to an input and that
+// values it cannot show are rejected.
+func TestForInput(t *testing.T) {
+	cfg, err := Config{Max: 0, Count: 30}.ForInput([]int{3, 12, 0})
+	if err != nil || cfg.Max != 12 || cfg.Count != 3 {
+		t.Errorf("Expected Max 12 and Count 3, got %+v, %v", cfg, err)
+	}
+	cfg, err = Config{Max: 20}.ForInput([]int{3, 12})
+	if err != nil || cfg.Max != 20 || cfg.Count != 2 {
+		t.Errorf("Expected Max 20 and Count 2, got %+v, %v", cfg, err)
+	}
+	cfg, err = Config{}.ForInput([]int{0, 0})
+	if err != nil || cfg.Max != 1 {
+		t.Errorf("Expected Max 1 for all zeros, got %+v, %v", cfg, err)
+	}
+	for _, arr := range [][]int{nil, {1, -2}, {1, 21}} {
+		if _, err := (Config{Max: 20}).ForInput(arr); err == nil {
+			t.Errorf("%v: expected an error", arr)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gsv
+
+// checkpointBudget limits the number of array elements the checkpoints of
+// a Player hold
+const checkpointBudget = 1 << 22
+
+// Player steps through the frames of a recorded Trace in both directions.
+// Frame 0 shows the input; every operation that changes the array starts a
+// new frame, as with Replay. Stepping backwards restarts from the nearest
+// checkpoint, which the Player saves every few frames.
+type Player struct {
+	tr     *Trace
+	ends   []int
+	stride int
+	checks []playerState
+
+	arr   []int
+	hl    highlight
+	frame int
+	pos   int
+}
+
+// playerState is a checkpoint of a Player
+type playerState struct {
+	arr []int
+	hl  highlight
+}
+
+// NewPlayer returns a Player positioned at the first frame of tr
+func NewPlayer(tr *Trace) *Player {
+	p := &Player{tr: tr, ends: []int{0}}
+	for k, e := range tr.Events {
+		if e.Mutates() {
+			p.ends = append(p.ends, k+1)
+		}
+	}
+	p.stride = 16
+	if n := len(p.ends) * len(tr.Input) / checkpointBudget; n > p.stride {
+		p.stride = n
+	}
+	p.arr = append([]int(nil), tr.Input...)
+	p.hl.reset()
+	p.save()
+	return p
+}
+
+// Frames returns the number of frames
+func (p *Player) Frames() int {
+	return len(p.ends)
+}
+
+// Frame returns the index of the current frame
+func (p *Player) Frame() int {
+	return p.frame
+}
+
+// Array returns the array of the current frame. It must not be changed
+// and is only valid until the Player moves.
+func (p *Player) Array() []int {
+	return p.arr
+}
+
+// Step moves by n frames, backwards if n is negative
+func (p *Player) Step(n int) {
+	p.Seek(p.frame + n)
+}
+
+// Seek moves to frame k, which is clamped to the existing frames
+func (p *Player) Seek(k int) {
+	if k < 0 {
+		k = 0
+	}
+	if k >= len(p.ends) {
+		k = len(p.ends) - 1
+	}
+	if k < p.frame || k/p.stride > p.frame/p.stride && k/p.stride < len(p.checks) {
+		p.restore(k / p.stride)
+	}
+	for p.frame < k {
+		p.advance()
+	}
+}
+
+// advance moves to the next frame and highlights the operations leading
+// to it
+func (p *Player) advance() {
+	p.hl.next()
+	for end := p.ends[p.frame+1]; p.pos < end; p.pos++ {
+		e := p.tr.Events[p.pos]
+		e.Apply(p.arr)
+		p.hl.add(e)
+	}
+	p.frame++
+	if p.frame%p.stride == 0 && p.frame/p.stride == len(p.checks) {
+		p.save()
+	}
+}
+
+// save appends a checkpoint of the current frame
+func (p *Player) save() {
+	p.checks = append(p.checks, playerState{
+		arr: append([]int(nil), p.arr...),
+		hl:  p.hl.clone(),
+	})
+}
+
+// restore moves to the frame of checkpoint c
+func (p *Player) restore(c int) {
+	copy(p.arr, p.checks[c].arr)
+	p.hl = p.checks[c].hl.clone()
+	p.frame = c * p.stride
+	p.pos = p.ends[p.frame]
+}
@@ -0,0 +1,53 @@
+package gsv
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestPlayer checks that seeking in any direction shows the frames and
+// highlights of a replay.
+func TestPlayer(t *testing.T) {
+	tr := Record("heap", gen.Random(100, 50, nil), HeapSortTraced)
+	var frames [][]int
+	tr.Replay(collectFrames(&frames))
+
+	p := NewPlayer(tr)
+	if p.Frames() != len(frames) {
+		t.Fatalf("Expected %d frames, got %d", len(frames), p.Frames())
+	}
+	roles := make([][]role, len(frames))
+	for k := range frames {
+		p.Seek(k)
+		if !reflect.DeepEqual(p.Array(), frames[k]) {
+			t.Fatalf("Frame %d differs from the replay", k)
+		}
+		for i := range p.Array() {
+			roles[k] = append(roles[k], p.hl.role(i))
+		}
+	}
+
+	for _, k := range rand.Perm(len(frames))[:200] {
+		p.Seek(k)
+		if p.Frame() != k || !reflect.DeepEqual(p.Array(), frames[k]) {
+			t.Fatalf("Seeking to frame %d shows frame %d", k, p.Frame())
+		}
+		for i := range p.Array() {
+			if r := p.hl.role(i); r != roles[k][i] {
+				t.Fatalf("Frame %d: index %d has role %d, expected %d", k, i, r, roles[k][i])
+			}
+		}
+	}
+
+	p.Step(-len(frames))
+	if p.Frame() != 0 || !reflect.DeepEqual(p.Array(), tr.Input) {
+		t.Errorf("Expected to stop at the input, got frame %d", p.Frame())
+	}
+	p.Step(2 * len(frames))
+	if p.Frame() != len(frames)-1 || !reflect.DeepEqual(p.Array(), tr.Result()) {
+		t.Errorf("Expected to stop at the result, got frame %d", p.Frame())
+	}
+}
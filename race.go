@@ -0,0 +1,294 @@
+package gsv
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Racer is a sorting algorithm taking part in a race
+type Racer struct {
+	Name string
+	Sort TraceSorter
+}
+
+// Alignment selects the clock by which the runs of a race are compared
+type Alignment int
+
+const (
+	// AlignOps advances all runs by the same number of operations, not
+	// counting marks, as Measure does
+	AlignOps Alignment = iota
+	// AlignTime advances all runs by the same wall time. The times are
+	// taken while the runs are recorded, so each operation also costs its
+	// recording; algorithms with many cheap operations fall behind.
+	AlignTime
+)
+
+// defaultRaceFrames is the number of frames of a race if Race.Frames is 0
+const defaultRaceFrames = 300
+
+// panelGap is the space between the panels of a race GIF in pixels
+const panelGap = 6
+
+// Race holds the runs of several sorting algorithms on the same input.
+// Play shows them side by side.
+type Race struct {
+	Lanes []Lane
+	// Align selects the clock the runs are shown by
+	Align Alignment
+	// Frames is the number of frames the race is shown in; 0 uses 300
+	Frames int
+}
+
+// Lane is the recorded run of one racer
+type Lane struct {
+	Trace *Trace
+	// Times holds the wall time from the start of the run to each event
+	Times []time.Duration
+	// Ops holds the number of operations up to and including each event;
+	// marks are not counted
+	Ops []int
+}
+
+// NewRace sorts a copy of input with each racer, one after another, and
+// records the runs
+func NewRace(input []int, racers ...Racer) *Race {
+	r := &Race{}
+	for _, rc := range racers {
+		lane := Lane{Trace: NewTrace(rc.Name, input)}
+		arr := append([]int(nil), input...)
+		start := time.Now()
+		ops := 0
+		rc.Sort(arr, TraceFunc(func(e Event) {
+			lane.Times = append(lane.Times, time.Since(start))
+			if e.Op != OpMark {
+				ops++
+			}
+			lane.Ops = append(lane.Ops, ops)
+			lane.Trace.Events = append(lane.Trace.Events, e)
+		}))
+		r.Lanes = append(r.Lanes, lane)
+	}
+	return r
+}
+
+// panelVisualizer is a visualizer that can show the runs of a race
+type panelVisualizer interface {
+	Visualizer
+	addPanels(panels []racePanel) error
+}
+
+// racePanel is the state of one run during the playback of a race
+type racePanel struct {
+	arr   []int
+	hl    highlight
+	pos   int
+	done  bool
+	label string
+}
+
+// Play shows the race with v, which must be a GifVisualizer or a
+// StdoutVisualizer. The runs are drawn in a grid, each labelled with its
+// name and, once it has finished, its rank and its operations or time.
+// GifOptions.Origin cannot follow the bars of several runs and is
+// rejected.
+func (r *Race) Play(v Visualizer) error {
+	pv, ok := v.(panelVisualizer)
+	if !ok {
+		return fmt.Errorf("gsv: %T cannot show races", v)
+	}
+	if gv, ok := v.(*GifVisualizer); ok && gv.Origin != nil {
+		return errors.New("gsv: races cannot colour bars by Origin")
+	}
+	if len(r.Lanes) == 0 {
+		return errors.New("gsv: race without runs")
+	}
+	names := make([]string, len(r.Lanes))
+	panels := make([]racePanel, len(r.Lanes))
+	for i, lane := range r.Lanes {
+		names[i] = lane.Trace.Name
+		panels[i].arr = append([]int(nil), lane.Trace.Input...)
+		panels[i].hl.reset()
+		panels[i].label = lane.Trace.Name
+	}
+	frames := r.Frames
+	if frames < 2 {
+		frames = defaultRaceFrames
+	}
+
+	err := v.Setup("race_" + strings.Join(names, "_"))
+	total, finished := r.length(), 0
+	for k := 0; k < frames && err == nil; k++ {
+		clock := total * float64(k) / float64(frames-1)
+		rank := finished + 1
+		for i := range panels {
+			p, lane := &panels[i], r.Lanes[i]
+			for end := r.position(i, clock); p.pos < end; p.pos++ {
+				e := lane.Trace.Events[p.pos]
+				e.Apply(p.arr)
+				p.hl.add(e)
+			}
+			if !p.done && p.pos == len(lane.Trace.Events) {
+				p.done = true
+				p.label = fmt.Sprintf("%s #%d %s", lane.Trace.Name, rank, r.result(i))
+				finished++
+			}
+		}
+		err = pv.addPanels(panels)
+		for i := range panels {
+			panels[i].hl.next()
+		}
+	}
+	if cerr := v.Complete(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// length returns the clock value at which the slowest run finishes
+func (r *Race) length() float64 {
+	var total float64
+	for i := range r.Lanes {
+		total = math.Max(total, r.clock(i, len(r.Lanes[i].Trace.Events)))
+	}
+	return total
+}
+
+// clock returns the clock value after the first n events of lane i
+func (r *Race) clock(i, n int) float64 {
+	switch {
+	case n == 0:
+		return 0
+	case r.Align == AlignTime:
+		return float64(r.Lanes[i].Times[n-1])
+	}
+	return float64(r.Lanes[i].Ops[n-1])
+}
+
+// position returns the number of events lane i has run at clock
+func (r *Race) position(i int, clock float64) int {
+	events := len(r.Lanes[i].Trace.Events)
+	return sort.Search(events, func(n int) bool {
+		return r.clock(i, n+1) > clock
+	})
+}
+
+// result describes how long lane i took
+func (r *Race) result(i int) string {
+	lane := r.Lanes[i]
+	if r.Align == AlignTime {
+		return lane.finish().String()
+	}
+	return fmt.Sprintf("%d ops", lane.ops())
+}
+
+// ops returns the number of operations of the run, not counting marks
+func (lane Lane) ops() int {
+	if len(lane.Ops) == 0 {
+		return 0
+	}
+	return lane.Ops[len(lane.Ops)-1]
+}
+
+// finish returns the time the run took until its last operation
+func (lane Lane) finish() time.Duration {
+	if len(lane.Times) == 0 {
+		return 0
+	}
+	d := lane.Times[len(lane.Times)-1]
+	if d > time.Millisecond {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// raceGrid returns the number of columns and rows of a grid of n panels
+func raceGrid(n int) (cols, rows int) {
+	cols = int(math.Ceil(math.Sqrt(float64(n))))
+	rows = (n + cols - 1) / cols
+	return cols, rows
+}
+
+// addPanels draws the panels of a race in a grid, each with a label above
+func (gv *GifVisualizer) addPanels(panels []racePanel) error {
+	if err := gv.ready(); err != nil {
+		return err
+	}
+	images := make([]*image.Paletted, len(panels))
+	for i := range panels {
+		images[i] = buildImage(*gv.cfg, gv.GifOptions, panels[i].arr, &panels[i].hl)
+	}
+	w, h := images[0].Rect.Dx(), images[0].Rect.Dy()
+	scale := 1 + w/300
+	labelHeight := (glyphHeight + 1) * scale
+	cols, rows := raceGrid(len(panels))
+	cellWidth, cellHeight := w+panelGap, labelHeight+h+panelGap
+	frame := image.NewPaletted(image.Rect(0, 0, cols*cellWidth-panelGap, rows*cellHeight-panelGap), gifPalette)
+	for i, img := range images {
+		x0, y0 := (i%cols)*cellWidth, (i/cols)*cellHeight
+		c := uint8(1)
+		if panels[i].done {
+			c = uint8(roleCompare) + 1
+		}
+		drawLabel(frame, x0, y0, w, panels[i].label, scale, c)
+		for y := 0; y < h; y++ {
+			copy(frame.Pix[frame.PixOffset(x0, y0+labelHeight+y):], img.Pix[img.PixOffset(0, y):img.PixOffset(w, y)])
+		}
+	}
+	return gv.addImage(frame)
+}
+
+// addPanels draws the panels of a race in a grid, each with a label above
+func (sv *StdoutVisualizer) addPanels(panels []racePanel) error {
+	sv.mu.Lock()
+	err := sv.err
+	sv.mu.Unlock()
+	if err != nil || sv.cfg.Quiet {
+		return err
+	}
+	frames := make([]*textFrame, len(panels))
+	for i := range panels {
+		var style *textStyle
+		if sv.style != nil {
+			st := *sv.style
+			st.hl = &panels[i].hl
+			style = &st
+		}
+		frames[i] = drawText(*sv.cfg, panels[i].arr, style)
+	}
+	w, h := frames[0].cols, frames[0].rows
+	cols, rows := raceGrid(len(panels))
+	grid := newTextFrame(cols*(w+1)-1, rows*(h+1), sv.style)
+	for i, f := range frames {
+		x0, y0 := (i%cols)*(w+1), (i/cols)*(h+1)
+		label := gifPalette[roleSorted+1].(color.Gray)
+		c := color.RGBA{label.Y, label.Y, label.Y, 255}
+		if panels[i].done {
+			c = gifPalette[roleCompare+1].(color.RGBA)
+		}
+		for x, r := range []rune(panels[i].label) {
+			if x >= w {
+				break
+			}
+			k := (x0+x)*grid.rows + y0
+			grid.cells[k] = r
+			if grid.colors != nil {
+				grid.colors[k] = c
+			}
+		}
+		for x := 0; x < w; x++ {
+			k := (x0+x)*grid.rows + y0 + 1
+			copy(grid.cells[k:k+h], f.column(x))
+			if grid.colors != nil {
+				copy(grid.colors[k:k+h], f.colors[x*h:(x+1)*h])
+			}
+		}
+	}
+	return sv.show(grid)
+}
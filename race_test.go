@@ -0,0 +1,108 @@
+package gsv
+
+import (
+	"bytes"
+	"fmt"
+	"image/gif"
+	"reflect"
+	"strings"
+	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestRace checks that all runs of a race start from the same input and
+// finish sorted, with the faster one finishing first.
+func TestRace(t *testing.T) {
+	input := gen.Random(40, 20, nil)
+	race := NewRace(input, Racer{"bubble", BubbleSortTraced}, Racer{"quick", QuickSortTraced})
+	for _, lane := range race.Lanes {
+		if !reflect.DeepEqual(lane.Trace.Input, input) {
+			t.Errorf("%s: Expected the shared input", lane.Trace.Name)
+		}
+		if len(lane.Times) != len(lane.Trace.Events) || len(lane.Ops) != len(lane.Trace.Events) {
+			t.Errorf("%s: Expected a time and a count for every event", lane.Trace.Name)
+		}
+	}
+	st := Measure("quick", input, QuickSortTraced)
+	ops := st.Comparisons + st.Swaps + st.Writes + st.AuxWrites
+	if got := race.Lanes[1].ops(); got != ops {
+		t.Errorf("Expected quick to take %d operations without marks, got %d", ops, got)
+	}
+
+	var out bytes.Buffer
+	sv := NewStdoutVisualizer(Config{Max: 20, Fps: 1000, Mode: ModeBars})
+	sv.Clear = ClearNone
+	sv.Color = ColorNone
+	sv.Out = &out
+	race.Frames = 50
+	if err := race.Play(sv); err != nil {
+		t.Fatal(err)
+	}
+	frames := strings.Split(strings.TrimSuffix(out.String(), "\n\n"), "\n\n")
+	if len(frames) != 50 {
+		t.Fatalf("Expected 50 frames, got %d", len(frames))
+	}
+	last := strings.Split(frames[len(frames)-1], "\n")
+	if !strings.HasPrefix(last[0], "bubble #2 ") || !strings.Contains(last[0], fmt.Sprintf(" quick #1 %d ops", ops)) {
+		t.Errorf("Expected quick to win, got labels %q", last[0])
+	}
+	if len(last) != 21 || len([]rune(last[0])) != 81 {
+		t.Errorf("Expected two panels side by side, got %d rows of %d", len(last), len([]rune(last[0])))
+	}
+}
+
+// TestRaceGif checks the size of a tiled race GIF.
+func TestRaceGif(t *testing.T) {
+	input := gen.Random(30, 9, nil)
+	race := NewRace(input,
+		Racer{"heap", HeapSortTraced},
+		Racer{"merge", MergeSortTraced},
+		Racer{"shell", ShellSortTraced})
+	race.Align = AlignTime
+	race.Frames = 20
+
+	var buf bytes.Buffer
+	gv := NewGifWriter(&buf, Config{Max: 9, Fps: 20, Mode: ModeBars})
+	gv.BarWidth = 3
+	gv.ValueHeight = 4
+	if err := race.Play(gv); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	width, height := 2*90+panelGap, 2*(glyphHeight+1+36)+panelGap
+	if g.Config.Width != width || g.Config.Height != height {
+		t.Errorf("Expected %dx%d, got %dx%d", width, height, g.Config.Width, g.Config.Height)
+	}
+
+	if err := race.Play(collectFrames(nil)); err == nil {
+		t.Error("Expected an error for a visualizer that cannot show races")
+	}
+
+	gv = NewGifWriter(&buf, Config{Max: 9})
+	gv.Origin = make([]int, len(input))
+	if err := race.Play(gv); err == nil || !strings.Contains(err.Error(), "Origin") {
+		t.Errorf("Expected an error for a race coloured by Origin, got %v", err)
+	}
+}
+
+// TestDrawLabel checks a label drawn with the tiny font.
+func TestDrawLabel(t *testing.T) {
+	img := buildImage(Config{Max: 5}, GifOptions{}, make([]int, 8), nil)
+	drawLabel(img, 0, 0, 8, "t1", 1, 1)
+	var rows []string
+	for y := 0; y < 5; y++ {
+		row := ""
+		for x := 0; x < 8; x++ {
+			row += map[uint8]string{0: ".", 1: "#"}[img.ColorIndexAt(x, y)]
+		}
+		rows = append(rows, row)
+	}
+	expected := []string{"###..#..", ".#..##..", ".#...#..", ".#...#..", ".#..###."}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(rows, "\n"))
+	}
+}
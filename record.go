@@ -0,0 +1,243 @@
+package gsv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// traceMagic starts every binary trace file
+const traceMagic = "GSVT"
+
+// traceVersion is the version of the binary trace format
+const traceVersion = 1
+
+// Trace is a recorded run of a sorting algorithm. It can be replayed into
+// any Visualizer without running the algorithm again.
+type Trace struct {
+	Name string `json:"name"`
+	// Seed is the seed the run drew from, if it was seeded
+	Seed   int64   `json:"seed,omitempty"`
+	Input  []int   `json:"input"`
+	Events []Event `json:"events"`
+}
+
+// NewTrace returns an empty trace for sorting arr. The trace keeps a copy
+// of arr as its input.
+func NewTrace(name string, arr []int) *Trace {
+	input := make([]int, len(arr))
+	copy(input, arr)
+	return &Trace{Name: name, Input: input}
+}
+
+// Record sorts arr with s and returns the trace of the run
+func Record(name string, arr []int, s TraceSorter) *Trace {
+	tr := NewTrace(name, arr)
+	s(arr, tr.Tracer())
+	return tr
+}
+
+// Tracer returns a Tracer that appends every operation to the trace
+func (tr *Trace) Tracer() Tracer {
+	return TraceFunc(func(e Event) {
+		tr.Events = append(tr.Events, e)
+	})
+}
+
+// Play applies the recorded events to arr and reports each of them to t.
+// arr must hold a copy of the trace input.
+func (tr *Trace) Play(arr []int, t Tracer) {
+	t = tracer(t)
+	for _, e := range tr.Events {
+		e.Apply(arr)
+		e.Report(t)
+	}
+}
+
+// Replay renders the recorded run with v and returns the first error of v
+func (tr *Trace) Replay(v Visualizer) error {
+	arr := make([]int, len(tr.Input))
+	copy(arr, tr.Input)
+	return Run(v, tr.Name, arr, tr.Play)
+}
+
+// Result returns the array the recorded run ended with
+func (tr *Trace) Result() []int {
+	arr := make([]int, len(tr.Input))
+	copy(arr, tr.Input)
+	tr.Play(arr, nil)
+	return arr
+}
+
+// Frames returns the number of frames a replay of the trace produces
+func (tr *Trace) Frames() int {
+	frames := 1
+	for _, e := range tr.Events {
+		if e.Mutates() {
+			frames++
+		}
+	}
+	return frames
+}
+
+// Validate checks that every event changing the array refers to an index
+// of the input, so the trace can be replayed safely
+func (tr *Trace) Validate() error {
+	n := len(tr.Input)
+	for k, e := range tr.Events {
+		switch e.Op {
+		case OpSwap:
+			if e.I < 0 || e.I >= n || e.J < 0 || e.J >= n {
+				return fmt.Errorf("gsv: event %d: swap %d, %d out of range", k, e.I, e.J)
+			}
+		case OpWrite:
+			if e.I < 0 || e.I >= n {
+				return fmt.Errorf("gsv: event %d: write %d out of range", k, e.I)
+			}
+		case OpCompare, OpAuxWrite, OpMark:
+		default:
+			return fmt.Errorf("gsv: event %d: unknown operation %d", k, e.Op)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the trace in the compact binary trace format
+func (tr *Trace) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(traceMagic)+1+len(tr.Name)+2*len(tr.Input)+3*len(tr.Events)+16)
+	b = append(b, traceMagic...)
+	b = append(b, traceVersion)
+	b = binary.AppendUvarint(b, uint64(len(tr.Name)))
+	b = append(b, tr.Name...)
+	b = binary.AppendVarint(b, tr.Seed)
+	b = binary.AppendUvarint(b, uint64(len(tr.Input)))
+	for _, v := range tr.Input {
+		b = binary.AppendVarint(b, int64(v))
+	}
+	b = binary.AppendUvarint(b, uint64(len(tr.Events)))
+	for _, e := range tr.Events {
+		b = append(b, byte(e.Op))
+		b = binary.AppendVarint(b, int64(e.I))
+		b = binary.AppendVarint(b, int64(e.J))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a trace written by MarshalBinary
+func (tr *Trace) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(traceMagic)) {
+		return errors.New("gsv: not a trace file")
+	}
+	r := bytes.NewReader(data[len(traceMagic):])
+	version, err := r.ReadByte()
+	if err != nil {
+		return errTraceTruncated
+	}
+	if version != traceVersion {
+		return fmt.Errorf("gsv: unsupported trace version %d", version)
+	}
+
+	n, err := readLength(r)
+	if err != nil {
+		return err
+	}
+	name := make([]byte, n)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return errTraceTruncated
+	}
+	seed, err := binary.ReadVarint(r)
+	if err != nil {
+		return errTraceTruncated
+	}
+
+	n, err = readLength(r)
+	if err != nil {
+		return err
+	}
+	input := make([]int, n)
+	for i := range input {
+		v, err := binary.ReadVarint(r)
+		if err != nil {
+			return errTraceTruncated
+		}
+		input[i] = int(v)
+	}
+
+	n, err = readLength(r)
+	if err != nil {
+		return err
+	}
+	events := make([]Event, n)
+	for k := range events {
+		op, err := r.ReadByte()
+		if err != nil {
+			return errTraceTruncated
+		}
+		i, err := binary.ReadVarint(r)
+		if err != nil {
+			return errTraceTruncated
+		}
+		j, err := binary.ReadVarint(r)
+		if err != nil {
+			return errTraceTruncated
+		}
+		events[k] = Event{Op(op), int(i), int(j)}
+	}
+
+	*tr = Trace{Name: string(name), Seed: seed, Input: input, Events: events}
+	return tr.Validate()
+}
+
+var errTraceTruncated = errors.New("gsv: truncated trace")
+
+// readLength reads a length prefix and checks it against the remaining data
+func readLength(r *bytes.Reader) (int, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, errTraceTruncated
+	}
+	if n > uint64(r.Len()) {
+		return 0, errTraceTruncated
+	}
+	return int(n), nil
+}
+
+// SaveTrace writes tr to path. Files ending in .json are written as JSON,
+// all others in the binary trace format.
+func SaveTrace(path string, tr *Trace) error {
+	var data []byte
+	var err error
+	if filepath.Ext(path) == ".json" {
+		data, err = json.Marshal(tr)
+	} else {
+		data, err = tr.MarshalBinary()
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadTrace reads a trace written by SaveTrace. The format is detected
+// from the file content.
+func LoadTrace(path string) (*Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tr := &Trace{}
+	if bytes.HasPrefix(data, []byte(traceMagic)) {
+		err = tr.UnmarshalBinary(data)
+	} else if err = json.Unmarshal(data, tr); err == nil {
+		err = tr.Validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tr, nil
+}
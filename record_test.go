@@ -0,0 +1,150 @@
+package gsv
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/gif"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestTraceEncoding checks that binary and JSON traces decode to the
+// recorded run.
+func TestTraceEncoding(t *testing.T) {
+	tr := Record("quick", gen.Random(12, 9, nil), QuickSortTraced)
+	tr.Seed = -42
+
+	data, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	binTrace := &Trace{}
+	if err := binTrace.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tr, binTrace) {
+		t.Errorf("binary round trip changed the trace")
+	}
+
+	data, err = json.Marshal(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonTrace := &Trace{}
+	if err := json.Unmarshal(data, jsonTrace); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tr, jsonTrace) {
+		t.Errorf("JSON round trip changed the trace")
+	}
+
+	if err := binTrace.UnmarshalBinary(data[:len(data)/2]); err == nil {
+		t.Error("Expected an error for a truncated trace")
+	}
+}
+
+// TestTraceBinary checks the layout of a binary trace and that other
+// versions are rejected.
+func TestTraceBinary(t *testing.T) {
+	data := []byte("GSVT\x01\x02ab\x06\x02\x04\x02\x01\x02\x00\x02")
+	tr := &Trace{}
+	if err := tr.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	expected := &Trace{Name: "ab", Seed: 3, Input: []int{2, 1}, Events: []Event{{OpSwap, 0, 1}}}
+	if !reflect.DeepEqual(tr, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, tr)
+	}
+	if b, _ := expected.MarshalBinary(); !bytes.Equal(b, data) {
+		t.Errorf("Expected %q, got %q", data, b)
+	}
+
+	data[4] = 2
+	if err := tr.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("Expected an error for version 2, got %v", err)
+	}
+}
+
+// TestTraceFiles checks that saved traces load in both formats.
+func TestTraceFiles(t *testing.T) {
+	dir := t.TempDir()
+	tr := Record("bogo", gen.Random(5, 9, nil), BogoSortTraced)
+
+	for _, name := range []string{"bogo.gsvt", "bogo.json"} {
+		path := filepath.Join(dir, name)
+		if err := SaveTrace(path, tr); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadTrace(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tr, loaded) {
+			t.Errorf("%s: loaded trace differs from the recorded one", name)
+		}
+	}
+}
+
+// TestTraceReplay checks that replaying a trace renders the same frames
+// as the original run.
+func TestTraceReplay(t *testing.T) {
+	arr := gen.Random(10, 9, nil)
+	var frames [][]int
+	collect := FrameGen(func(arr []int) {
+		frames = append(frames, cloneArray(arr))
+	})
+
+	tr := NewTrace("heap", arr)
+	HeapSortTraced(arr, MultiTracer(FrameTracer(arr, collect), tr.Tracer()))
+	original := frames
+
+	frames = nil
+	tr.Replay(collect)
+	if !reflect.DeepEqual(original, frames) {
+		t.Errorf("Expected %d replayed frames to match, got %d", len(original), len(frames))
+	}
+	if !reflect.DeepEqual(tr.Result(), arr) {
+		t.Errorf("Expected result %v, got %v", arr, tr.Result())
+	}
+}
+
+// TestTraceValidate checks that traces with out of range events are rejected.
+func TestTraceValidate(t *testing.T) {
+	tr := &Trace{Input: []int{1, 2}, Events: []Event{{OpSwap, 0, 2}}}
+	if tr.Validate() == nil {
+		t.Error("Expected an error for a swap out of range")
+	}
+}
+
+// TestSeed checks that runs from the same seed are identical and that GIFs
+// record the seed.
+func TestSeed(t *testing.T) {
+	run := func(seed int64) *Trace {
+		rng := rand.New(rand.NewSource(seed))
+		tr := Record("bogo", gen.Random(6, 9, rng), BogoSortRand(rng))
+		tr.Seed = seed
+		return tr
+	}
+	if a, b := run(7), run(7); !reflect.DeepEqual(a, b) {
+		t.Error("Expected the same run for the same seed")
+	}
+	if a, b := run(7), run(8); reflect.DeepEqual(a.Events, b.Events) {
+		t.Error("Expected different runs for different seeds")
+	}
+
+	var buf bytes.Buffer
+	if err := run(7).Replay(NewGifWriter(&buf, Config{Max: 9, Fps: 10, Seed: 7})); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("bogo seed=7")) {
+		t.Error("Expected the seed in a GIF comment")
+	}
+	if _, err := gif.DecodeAll(&buf); err != nil {
+		t.Error(err)
+	}
+}
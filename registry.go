@@ -0,0 +1,251 @@
+package gsv
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"text/tabwriter"
+)
+
+// Algorithm describes a sorting algorithm and how it can be used
+type Algorithm struct {
+	// Name identifies the algorithm, e.g. on the command line
+	Name string
+	// Title is the name shown in documentation, e.g. "QuickSort"
+	Title string
+	// URL points to a description of the algorithm
+	URL   string
+	Sort  Sorter
+	Trace TraceSorter
+	// Rand, if not nil, returns Trace drawing its random numbers from rng
+	Rand func(rng *rand.Rand) TraceSorter
+
+	// Stable is set if equal values keep their order
+	Stable bool
+	// InPlace is set if the algorithm needs no auxiliary array
+	InPlace bool
+	// Comparison is set if the algorithm orders by comparing elements
+	Comparison bool
+	// Best, Average and Worst are the time complexities and Memory the
+	// auxiliary space, e.g. "n log n"
+	Best, Average, Worst, Memory string
+
+	// MaxN is the largest number of values sorted in reasonable time;
+	// 0 for no limit
+	MaxN int
+	// MaxValue is the highest value sorted in reasonable time; 0 for no
+	// limit
+	MaxValue int
+	// NonNegative is set if the algorithm cannot sort negative values
+	NonNegative bool
+	// PowerOfTwo is set if the number of values must be a power of two
+	PowerOfTwo bool
+}
+
+// Check reports whether the algorithm can sort arr
+func (a Algorithm) Check(arr []int) error {
+	n := len(arr)
+	switch {
+	case a.MaxN > 0 && n > a.MaxN:
+		return fmt.Errorf("gsv: %s sorts at most %d values, not %d", a.Name, a.MaxN, n)
+	case a.PowerOfTwo && n&(n-1) != 0:
+		return fmt.Errorf("gsv: %s needs a power of two values, not %d", a.Name, n)
+	}
+	for _, v := range arr {
+		switch {
+		case a.NonNegative && v < 0:
+			return fmt.Errorf("gsv: %s cannot sort the negative value %d", a.Name, v)
+		case a.MaxValue > 0 && v > a.MaxValue:
+			return fmt.Errorf("gsv: %s sorts values up to %d, not %d", a.Name, a.MaxValue, v)
+		}
+	}
+	return nil
+}
+
+// registry holds the registered algorithms by name
+var registry = map[string]Algorithm{}
+
+// Register adds a to the algorithms listed by Algorithms. It panics if
+// the name is empty or already taken, or if Sort or Trace is missing.
+func Register(a Algorithm) {
+	if a.Name == "" || a.Sort == nil || a.Trace == nil {
+		panic("gsv: Register needs a name, Sort and Trace")
+	}
+	if _, dup := registry[a.Name]; dup {
+		panic("gsv: Register called twice for " + a.Name)
+	}
+	registry[a.Name] = a
+}
+
+// Lookup returns the algorithm registered under name
+func Lookup(name string) (Algorithm, bool) {
+	a, ok := registry[name]
+	return a, ok
+}
+
+// Algorithms returns the registered algorithms ordered by name
+func Algorithms() []Algorithm {
+	list := make([]Algorithm, 0, len(registry))
+	for _, name := range Names() {
+		list = append(list, registry[name])
+	}
+	return list
+}
+
+// Names returns the names of the registered algorithms in alphabetical
+// order
+func Names() []string {
+	return sortedNames(registry)
+}
+
+func init() {
+	for _, a := range []Algorithm{
+		{Name: "bogo", Title: "BogoSort", URL: "https://en.wikipedia.org/wiki/Bogosort",
+			Sort: BogoSort, Trace: BogoSortTraced, Rand: BogoSortRand,
+			InPlace: true, Comparison: true,
+			Best: "n", Average: "n·n!", Worst: "∞", Memory: "1", MaxN: 10},
+		{Name: "bubble", Title: "BubbleSort", URL: "https://en.wikipedia.org/wiki/Bubble_sort",
+			Sort: BubbleSort, Trace: BubbleSortTraced,
+			Stable: true, InPlace: true, Comparison: true,
+			Best: "n^2", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "cocktail", Title: "CocktailSort", URL: "https://en.wikipedia.org/wiki/Cocktail_shaker_sort",
+			Sort: CocktailSort, Trace: CocktailSortTraced,
+			Stable: true, InPlace: true, Comparison: true,
+			Best: "n", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "comb", Title: "CombSort", URL: "https://en.wikipedia.org/wiki/Comb_sort",
+			Sort: CombSort, Trace: CombSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n log n", Average: "n^2/2^p", Worst: "n^2", Memory: "1"},
+		{Name: "counting", Title: "CountingSort", URL: "https://en.wikipedia.org/wiki/Counting_sort",
+			Sort: CountingSort, Trace: CountingSortTraced,
+			Stable: true,
+			Best:   "n+k", Average: "n+k", Worst: "n+k", Memory: "k", NonNegative: true},
+		{Name: "cycle", Title: "CycleSort", URL: "https://en.wikipedia.org/wiki/Cycle_sort",
+			Sort: CycleSort, Trace: CycleSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n^2", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "gnome", Title: "GnomeSort", URL: "https://en.wikipedia.org/wiki/Gnome_sort",
+			Sort: GnomeSort, Trace: GnomeSortTraced,
+			Stable: true, InPlace: true, Comparison: true,
+			Best: "n", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "insertion", Title: "InsertionSort", URL: "https://en.wikipedia.org/wiki/Insertion_sort",
+			Sort: InsertionSort, Trace: InsertionSortTraced,
+			Stable: true, InPlace: true, Comparison: true,
+			Best: "n", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "oddEven", Title: "OddEvenSort", URL: "https://en.wikipedia.org/wiki/Odd–even_sort",
+			Sort: OddEvenSort, Trace: OddEvenSortTraced,
+			Stable: true, InPlace: true, Comparison: true,
+			Best: "n", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "selection", Title: "SelectionSort", URL: "https://en.wikipedia.org/wiki/Selection_sort",
+			Sort: SelectionSort, Trace: SelectionSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n^2", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "sleep", Title: "SleepSort", URL: "https://rosettacode.org/wiki/Sorting_algorithms/Sleep_sort",
+			Sort: SleepSort, Trace: SleepSortTraced,
+			Best: "n+k", Average: "n+k", Worst: "n+k", Memory: "n",
+			MaxValue: 1000, NonNegative: true},
+		{Name: "stooge", Title: "StoogeSort", URL: "https://en.wikipedia.org/wiki/Stooge_sort",
+			Sort: StoogeSort, Trace: StoogeSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n^2.71", Average: "n^2.71", Worst: "n^2.71", Memory: "log n", MaxN: 1000},
+		{Name: "pancake", Title: "PancakeSort", URL: "https://en.wikipedia.org/wiki/Pancake_sorting",
+			Sort: PancakeSort, Trace: PancakeSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n^2", Average: "n^2", Worst: "n^2", Memory: "1"},
+		{Name: "quick", Title: "QuickSort", URL: "https://en.wikipedia.org/wiki/Quicksort",
+			Sort: QuickSort, Trace: QuickSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n log n", Average: "n log n", Worst: "n^2", Memory: "log n"},
+		{Name: "merge", Title: "MergeSort", URL: "https://en.wikipedia.org/wiki/Merge_sort",
+			Sort: MergeSort, Trace: MergeSortTraced,
+			Stable: true, Comparison: true,
+			Best: "n log n", Average: "n log n", Worst: "n log n", Memory: "n"},
+		{Name: "shell", Title: "ShellSort", URL: "https://en.wikipedia.org/wiki/Shellsort",
+			Sort: ShellSort, Trace: ShellSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n log n", Average: "n^1.5", Worst: "n^2", Memory: "1"},
+		{Name: "heap", Title: "HeapSort", URL: "https://en.wikipedia.org/wiki/Heapsort",
+			Sort: HeapSort, Trace: HeapSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n log n", Average: "n log n", Worst: "n log n", Memory: "1"},
+		{Name: "radix", Title: "RadixSort", URL: "https://en.wikipedia.org/wiki/Radix_sort",
+			Sort: RadixSort, Trace: RadixSortTraced,
+			Stable: true,
+			Best:   "n·d", Average: "n·d", Worst: "n·d", Memory: "n", NonNegative: true},
+		{Name: "bitonic", Title: "BitonicSort", URL: "https://en.wikipedia.org/wiki/Bitonic_sorter",
+			Sort: BitonicSort, Trace: BitonicSortTraced,
+			InPlace: true, Comparison: true,
+			Best: "n log^2 n", Average: "n log^2 n", Worst: "n log^2 n", Memory: "log n"},
+	} {
+		Register(a)
+	}
+}
+
+// WriteAlgorithms lists the registered algorithms with their properties
+// as a "text" or a "markdown" table
+func WriteAlgorithms(w io.Writer, format string) error {
+	header := []string{"name", "algorithm", "best", "average", "worst", "memory", "stable", "in place", "limits"}
+	rows := [][]string{header}
+	for _, a := range Algorithms() {
+		title := a.Title
+		if format == "markdown" {
+			title = "[" + a.Title + "](" + a.URL + ")"
+		}
+		rows = append(rows, []string{a.Name, title, a.Best, a.Average, a.Worst, a.Memory,
+			yesNo(a.Stable), yesNo(a.InPlace), a.limits()})
+	}
+
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("gsv: %w", err)
+		}
+	case "markdown":
+		rule := make([]string, len(header))
+		for i := range rule {
+			rule[i] = "---"
+		}
+		rows = append([][]string{header, rule}, rows[1:]...)
+		for _, row := range rows {
+			if _, err := fmt.Fprintln(w, "| "+strings.Join(row, " | ")+" |"); err != nil {
+				return fmt.Errorf("gsv: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("gsv: unknown algorithm list format %q", format)
+	}
+	return nil
+}
+
+// limits describes the input constraints of a
+func (a Algorithm) limits() string {
+	var limits []string
+	if a.MaxN > 0 {
+		limits = append(limits, fmt.Sprintf("n ≤ %d", a.MaxN))
+	}
+	if a.PowerOfTwo {
+		limits = append(limits, "n = 2^k")
+	}
+	if a.NonNegative {
+		limits = append(limits, "values ≥ 0")
+	}
+	if a.MaxValue > 0 {
+		limits = append(limits, fmt.Sprintf("values ≤ %d", a.MaxValue))
+	}
+	if len(limits) == 0 {
+		return "-"
+	}
+	return strings.Join(limits, ", ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,96 @@
+package gsv
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestRegistry checks the lookup functions and the input constraints.
+func TestRegistry(t *testing.T) {
+	names := Names()
+	if len(names) != len(Algorithms()) || len(names) < 19 {
+		t.Fatalf("Expected all algorithms to be registered, got %v", names)
+	}
+	for i, a := range Algorithms() {
+		if a.Name != names[i] {
+			t.Errorf("Expected %s at %d, got %s", names[i], i, a.Name)
+		}
+		if a.Title == "" || !strings.HasPrefix(a.URL, "https://") || a.Best == "" || a.Average == "" || a.Worst == "" || a.Memory == "" {
+			t.Errorf("%s: incomplete metadata %+v", a.Name, a)
+		}
+		if got, ok := Lookup(a.Name); !ok || got.Title != a.Title {
+			t.Errorf("%s: Lookup failed", a.Name)
+		}
+	}
+	if _, ok := Lookup("missing"); ok {
+		t.Error("Expected Lookup to fail for an unknown name")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected Register to panic for a taken name")
+			}
+		}()
+		Register(Algorithm{Name: "quick", Sort: QuickSort, Trace: QuickSortTraced})
+	}()
+
+	bogo, _ := Lookup("bogo")
+	radix, _ := Lookup("radix")
+	sleep, _ := Lookup("sleep")
+	quick, _ := Lookup("quick")
+	tests := []struct {
+		a   Algorithm
+		arr []int
+		ok  bool
+	}{
+		{bogo, make([]int, 10), true},
+		{bogo, make([]int, 11), false},
+		{radix, []int{3, 0}, true},
+		{radix, []int{3, -1}, false},
+		{sleep, []int{1000}, true},
+		{sleep, []int{1001}, false},
+		{quick, []int{-5, math.MaxInt}, true},
+	}
+	for _, test := range tests {
+		if err := test.a.Check(test.arr); (err == nil) != test.ok {
+			t.Errorf("%s: Check(%v) returned %v", test.a.Name, test.arr, err)
+		}
+	}
+}
+
+// TestReadme checks that readme.md lists every registered algorithm and
+// holds the current algorithm table.
+func TestReadme(t *testing.T) {
+	data, err := os.ReadFile("readme.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	readme := string(data)
+	for _, a := range Algorithms() {
+		if !strings.Contains(readme, "### "+a.Title+"\n") {
+			t.Errorf("Expected a section for %s", a.Title)
+		}
+	}
+	var table bytes.Buffer
+	if err := WriteAlgorithms(&table, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(readme, table.String()) {
+		t.Errorf("Expected the readme to contain the table of go run demo/main.go -list=markdown:\n%s", table.String())
+	}
+
+	table.Reset()
+	if err := WriteAlgorithms(&table, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(table.String(), "\n"); lines != len(Algorithms())+1 {
+		t.Errorf("Expected %d lines, got %d", len(Algorithms())+1, lines)
+	}
+	if err := WriteAlgorithms(&table, "html"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,232 @@
+package gsv
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// checkSorted reports an error unless got is input in ascending order
+func checkSorted(t *testing.T, name string, input, got []int) {
+	t.Helper()
+	want := slices.Clone(input)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: sorted %v to %v", name, input, got)
+	}
+}
+
+// checkValues reports an error unless got holds the values of input in
+// any order
+func checkValues(t *testing.T, name string, input, got []int) {
+	t.Helper()
+	checkSorted(t, name, input, slices.Sorted(slices.Values(got)))
+}
+
+// checker returns the check of the results of a. SleepSort is only
+// checked to keep the values, as the scheduler may wake close values in
+// the wrong order; TestSleepSort checks it on values far apart.
+func checker(a Algorithm) func(t *testing.T, name string, input, got []int) {
+	if a.Name == "sleep" {
+		return checkValues
+	}
+	return checkSorted
+}
+
+// sortCases are inputs at the edges of what the algorithms have to handle
+var sortCases = []struct {
+	name string
+	arr  []int
+}{
+	{"empty", []int{}},
+	{"nil", nil},
+	{"single", []int{7}},
+	{"zero", []int{0}},
+	{"two sorted", []int{1, 2}},
+	{"two reversed", []int{2, 1}},
+	{"two equal", []int{4, 4}},
+	{"three", []int{3, 1, 2}},
+	{"all equal", []int{5, 5, 5, 5, 5, 5, 5}},
+	{"duplicates", []int{3, 1, 3, 0, 1, 3, 2, 0}},
+	{"sorted", []int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+	{"reversed", []int{8, 7, 6, 5, 4, 3, 2, 1, 0}},
+	{"odd length", []int{9, 2, 7, 4, 5, 6, 3, 8, 1, 0, 5}},
+	{"power of two", []int{6, 0, 7, 3, 1, 5, 2, 4}},
+	{"max value", []int{1000, 0, 999, 1000, 1}},
+	{"large values", []int{1 << 20, 3, 1<<20 - 1, 10, 100000}},
+	{"negative", []int{-3, 5, 0, -3, -10, 2}},
+	{"long odd", gen.Random(31, 9, rand.New(rand.NewSource(1)))},
+	{"long even", gen.Random(100, 50, rand.New(rand.NewSource(2)))},
+}
+
+// TestSortCases sorts the edge cases with every registered algorithm that
+// accepts them, through Sort, Trace and the generic version, and checks
+// that the events of the trace reproduce the result.
+func TestSortCases(t *testing.T) {
+	for _, a := range Algorithms() {
+		t.Run(a.Name, func(t *testing.T) {
+			t.Parallel()
+			check := checker(a)
+			for _, c := range sortCases {
+				if a.Check(c.arr) != nil {
+					continue
+				}
+				name := a.Name + "/" + c.name
+
+				arr := slices.Clone(c.arr)
+				a.Sort(arr, nil)
+				check(t, name+"/Sort", c.arr, arr)
+
+				tr := Record(a.Name, slices.Clone(c.arr), a.Trace)
+				check(t, name+"/Trace", c.arr, tr.Result())
+				if err := tr.Validate(); err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+
+				k := Keyed[int]{S: slices.Clone(c.arr), Key: func(v int) int { return v }}
+				s, err := k.Sorter(a.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys := k.Keys()
+				s(keys, nil)
+				check(t, name+"/Keyed", c.arr, k.S)
+				check(t, name+"/Keys", c.arr, keys)
+			}
+		})
+	}
+}
+
+// TestSortProperties sorts random inputs of random length from every
+// generator and checks that the result is the sorted input.
+func TestSortProperties(t *testing.T) {
+	for _, a := range Algorithms() {
+		t.Run(a.Name, func(t *testing.T) {
+			t.Parallel()
+			maxN, maxValue := 64, 64
+			switch {
+			case a.Rand != nil:
+				maxN = 6
+			case a.MaxValue > 0:
+				maxValue = 16
+			}
+			names := gen.Names()
+			property := func(seed int64) bool {
+				rng := rand.New(rand.NewSource(seed))
+				input := gen.Generators[names[rng.Intn(len(names))]](rng.Intn(maxN+1), 1+rng.Intn(maxValue), rng)
+				if err := a.Check(input); err != nil {
+					t.Fatal(err)
+				}
+				arr := slices.Clone(input)
+				a.Trace(arr, nil)
+				if a.Name == "sleep" {
+					arr = slices.Sorted(slices.Values(arr))
+				}
+				want := slices.Clone(input)
+				slices.Sort(want)
+				if !slices.Equal(arr, want) {
+					t.Logf("sorted %v to %v", input, arr)
+					return false
+				}
+				return true
+			}
+			if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestSleepSort checks that SleepSort sorts values that are far enough
+// apart for the scheduler to keep them in order, even on a busy machine.
+func TestSleepSort(t *testing.T) {
+	input := rand.New(rand.NewSource(1)).Perm(6)
+	for i := range input {
+		input[i] *= 100
+	}
+	arr := slices.Clone(input)
+	SleepSort(arr, nil)
+	checkSorted(t, "sleep/Sort", input, arr)
+
+	tr := Record("sleep", slices.Clone(input), SleepSortTraced)
+	checkSorted(t, "sleep/Trace", input, tr.Result())
+
+	arr = slices.Clone(input)
+	SleepSortKey(arr, func(v int) int { return v })
+	checkSorted(t, "sleep/Key", input, arr)
+}
+
+// fuzzInput turns fuzzer bytes into signed values
+func fuzzInput(data []byte) []int {
+	arr := make([]int, len(data))
+	for i, b := range data {
+		arr[i] = int(int8(b))
+	}
+	return arr
+}
+
+// FuzzSort sorts arbitrary values with every algorithm that accepts them.
+// Randomized algorithms only get short inputs and SleepSort none, as they
+// would take too long.
+func FuzzSort(f *testing.F) {
+	for _, seed := range []string{"", "\x00", "\x02\x01", "\x05\x05\x05", "\x03\xff\x00\x80\x7f\x03", "hello, world"} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		input := fuzzInput(data)
+		for _, a := range Algorithms() {
+			if a.Check(input) != nil || a.Name == "sleep" || (a.Rand != nil && len(input) > 6) {
+				continue
+			}
+			tr := Record(a.Name, slices.Clone(input), a.Trace)
+			checkSorted(t, a.Name, input, tr.Result())
+			if err := tr.Validate(); err != nil {
+				t.Errorf("%s: %v", a.Name, err)
+			}
+		}
+	})
+}
+
+// FuzzSortStrings sorts the words of arbitrary text with the generic
+// comparison sorts.
+func FuzzSortStrings(f *testing.F) {
+	for _, seed := range []string{"", "a", "b a", "pear fig apple fig", "Z z 0 ä"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		words := strings.Fields(text)
+		want := slices.Clone(words)
+		slices.Sort(want)
+		for name, s := range genericSorts {
+			got := slices.Clone(words)
+			s.ordered(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s: sorted %q to %q", name, words, got)
+			}
+		}
+	})
+}
+
+// TestKillerInput checks that gen.Killer makes QuickSort quadratic even
+// though the input is not sorted.
+func TestKillerInput(t *testing.T) {
+	const n = 200
+	for name, input := range map[string]gen.Generator{"killer": gen.Killer, "random": gen.Random} {
+		arr := input(n, 1000, nil)
+		unsorted := !intSorter(arr, nil).isSorted()
+		compares := 0
+		QuickSortTraced(arr, TraceFunc(func(e Event) {
+			if e.Op == OpCompare {
+				compares++
+			}
+		}))
+		quadratic := compares >= n*n/5
+		if !unsorted || quadratic != (name == "killer") {
+			t.Errorf("%s: %d comparisons for %d values", name, compares, n)
+		}
+	}
+}
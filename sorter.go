@@ -0,0 +1,155 @@
+package gsv
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+)
+
+// sorter is a slice being sorted by one of the generic algorithms. Each
+// operation is reported to t, written elements with the value key
+// returns for them.
+type sorter[T any] struct {
+	s   []T
+	cmp func(a, b T) int
+	key func(T) int
+	t   Tracer
+	// rng is the source of randomized algorithms; nil uses the global one
+	rng *rand.Rand
+}
+
+// newSorter returns a sorter for s. A nil key reports 0 for every
+// element and a nil t discards all operations.
+func newSorter[T any](s []T, cmp func(a, b T) int, key func(T) int, t Tracer) *sorter[T] {
+	if key == nil {
+		key = func(T) int { return 0 }
+	}
+	return &sorter[T]{s: s, cmp: cmp, key: key, t: tracer(t)}
+}
+
+// intSorter returns the sorter behind the functions sorting []int
+func intSorter(arr []int, t Tracer) *sorter[int] {
+	return newSorter(arr, cmp.Compare[int], func(v int) int { return v }, t)
+}
+
+// greater compares the elements at i and j and reports whether the first
+// is greater
+func (st *sorter[T]) greater(i, j int) bool {
+	st.t.Compare(i, j)
+	return st.cmp(st.s[i], st.s[j]) > 0
+}
+
+// less compares the elements at i and j and reports whether the first
+// is smaller
+func (st *sorter[T]) less(i, j int) bool {
+	st.t.Compare(i, j)
+	return st.cmp(st.s[i], st.s[j]) < 0
+}
+
+// swap exchanges the elements at i and j
+func (st *sorter[T]) swap(i, j int) {
+	st.s[i], st.s[j] = st.s[j], st.s[i]
+	st.t.Swap(i, j)
+}
+
+// set writes v to index i
+func (st *sorter[T]) set(i int, v T) {
+	st.s[i] = v
+	st.t.Write(i, st.key(v))
+}
+
+// setAux writes v to index i of the auxiliary buffer aux
+func (st *sorter[T]) setAux(aux []T, i int, v T) {
+	aux[i] = v
+	st.t.AuxWrite(i, st.key(v))
+}
+
+// Keyed is a slice of any element type prepared for visualization. Cmp
+// orders the elements and Key maps each of them to the height of its bar;
+// a nil Cmp orders them by Key.
+type Keyed[T any] struct {
+	S   []T
+	Cmp func(a, b T) int
+	Key func(T) int
+}
+
+// Keys returns the bar heights of the elements of S
+func (k Keyed[T]) Keys() []int {
+	keys := make([]int, len(k.S))
+	for i, v := range k.S {
+		keys[i] = k.Key(v)
+	}
+	return keys
+}
+
+// Sorter returns a TraceSorter running the algorithm registered as name
+// on S. It expects the array it is given to hold Keys, in any order, and
+// keeps it in step with S, so the result can be passed to Run or Record
+// like any other algorithm and called more than once. Each call first
+// arranges the elements S held when Sorter was called in the order of the
+// array; elements of equal key keep their order.
+func (k Keyed[T]) Sorter(name string) (TraceSorter, error) {
+	sort, ok := keyedSort[T](name)
+	if !ok {
+		return nil, fmt.Errorf("gsv: no generic version of %q", name)
+	}
+	order := k.Cmp
+	if order == nil {
+		order = func(a, b T) int { return cmp.Compare(k.Key(a), k.Key(b)) }
+	}
+	elems := append([]T(nil), k.S...)
+	return func(arr []int, t Tracer) {
+		k.arrange(elems, arr)
+		keys := TraceFunc(func(e Event) { e.Apply(arr) })
+		st := newSorter(k.S, order, k.Key, MultiTracer(keys, tracer(t)))
+		sort(st)
+	}, nil
+}
+
+// arrange sets S to the elements of elems in the order of their keys in
+// arr. It panics if arr does not hold the keys of elems.
+func (k Keyed[T]) arrange(elems []T, arr []int) {
+	if len(arr) != len(elems) || len(k.S) != len(elems) {
+		panic(fmt.Sprintf("gsv: %d keys for %d elements", len(arr), len(elems)))
+	}
+	byKey := map[int][]T{}
+	for _, v := range elems {
+		key := k.Key(v)
+		byKey[key] = append(byKey[key], v)
+	}
+	for i, key := range arr {
+		vs := byKey[key]
+		if len(vs) == 0 {
+			panic(fmt.Sprintf("gsv: key %d does not belong to an element", key))
+		}
+		k.S[i], byKey[key] = vs[0], vs[1:]
+	}
+}
+
+// keyedSort returns the method of sorter running the algorithm registered
+// as name
+func keyedSort[T any](name string) (func(*sorter[T]), bool) {
+	sorts := map[string]func(*sorter[T]){
+		"bogo":      (*sorter[T]).bogo,
+		"bubble":    (*sorter[T]).bubble,
+		"cocktail":  (*sorter[T]).cocktail,
+		"comb":      (*sorter[T]).comb,
+		"counting":  (*sorter[T]).counting,
+		"cycle":     (*sorter[T]).cycle,
+		"gnome":     (*sorter[T]).gnome,
+		"insertion": (*sorter[T]).insertion,
+		"oddEven":   (*sorter[T]).oddEven,
+		"selection": (*sorter[T]).selection,
+		"sleep":     (*sorter[T]).sleep,
+		"stooge":    func(st *sorter[T]) { st.stooge(0, len(st.s)-1) },
+		"pancake":   (*sorter[T]).pancake,
+		"quick":     func(st *sorter[T]) { st.quick(0, len(st.s)-1) },
+		"merge":     (*sorter[T]).mergeSort,
+		"shell":     (*sorter[T]).shell,
+		"heap":      (*sorter[T]).heap,
+		"radix":     (*sorter[T]).radix,
+		"bitonic":   func(st *sorter[T]) { st.bitonicSort(0, len(st.s), 1) },
+	}
+	sort, ok := sorts[name]
+	return sort, ok
+}
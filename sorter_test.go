@@ -0,0 +1,164 @@
+package gsv
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// genericSorts are the exported generic versions of the comparison
+// algorithms
+var genericSorts = map[string]struct {
+	ordered func([]string)
+	fn      func([]string, func(a, b string) int)
+}{
+	"bubble":    {BubbleSortOrdered[string], BubbleSortFunc[string]},
+	"cocktail":  {CocktailSortOrdered[string], CocktailSortFunc[string]},
+	"comb":      {CombSortOrdered[string], CombSortFunc[string]},
+	"cycle":     {CycleSortOrdered[string], CycleSortFunc[string]},
+	"gnome":     {GnomeSortOrdered[string], GnomeSortFunc[string]},
+	"insertion": {InsertionSortOrdered[string], InsertionSortFunc[string]},
+	"oddEven":   {OddEvenSortOrdered[string], OddEvenSortFunc[string]},
+	"selection": {SelectionSortOrdered[string], SelectionSortFunc[string]},
+	"stooge":    {StoogeSortOrdered[string], StoogeSortFunc[string]},
+	"pancake":   {PancakeSortOrdered[string], PancakeSortFunc[string]},
+	"quick":     {QuickSortOrdered[string], QuickSortFunc[string]},
+	"merge":     {MergeSortOrdered[string], MergeSortFunc[string]},
+	"shell":     {ShellSortOrdered[string], ShellSortFunc[string]},
+	"heap":      {HeapSortOrdered[string], HeapSortFunc[string]},
+	"bitonic":   {BitonicSortOrdered[string], BitonicSortFunc[string]},
+}
+
+// TestGenericStrings checks the Ordered and Func versions on strings, in
+// ascending and in descending order.
+func TestGenericStrings(t *testing.T) {
+	words := strings.Fields("pear fig apple kiwi plum date lime banana")
+	want := slices.Clone(words)
+	slices.Sort(want)
+	reversed := slices.Clone(want)
+	slices.Reverse(reversed)
+	for name, s := range genericSorts {
+		got := slices.Clone(words)
+		s.ordered(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: Ordered returned %v", name, got)
+		}
+		got = slices.Clone(words)
+		s.fn(got, func(a, b string) int { return cmp.Compare(b, a) })
+		if !slices.Equal(got, reversed) {
+			t.Errorf("%s: Func returned %v", name, got)
+		}
+	}
+	got := []string{"b", "a"}
+	BogoSortOrdered(got)
+	if !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("bogo: Ordered returned %v", got)
+	}
+}
+
+// TestGenericKeys checks the algorithms that sort by key on structs and
+// that they keep equal keys in order.
+func TestGenericKeys(t *testing.T) {
+	type item struct {
+		name string
+		size int
+	}
+	items := []item{{"e", 30}, {"a", 7}, {"c", 120}, {"b", 7}, {"d", 0}}
+	want := []item{{"d", 0}, {"a", 7}, {"b", 7}, {"e", 30}, {"c", 120}}
+	size := func(it item) int { return it.size }
+	for name, sort := range map[string]func([]item, func(item) int){
+		"counting": CountingSortKey[item],
+		"radix":    RadixSortKey[item],
+	} {
+		got := slices.Clone(items)
+		sort(got, size)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v", name, got)
+		}
+	}
+	got := slices.Clone(items)
+	SleepSortKey(got, size)
+	for i := range got {
+		if got[i].size != want[i].size {
+			t.Errorf("sleep: got %v", got)
+			break
+		}
+	}
+}
+
+// TestGenericEvents checks that the int versions report the same
+// operations as the generic code they are built on.
+func TestGenericEvents(t *testing.T) {
+	arr := []int{5, 1, 4, 1, 3, 9, 2, 6}
+	for _, a := range Algorithms() {
+		if a.Name == "bogo" || a.Name == "sleep" {
+			continue
+		}
+		ints := Record(a.Name, cloneArray(arr), a.Trace)
+		k := Keyed[int]{S: cloneArray(arr), Key: func(v int) int { return v }}
+		s, err := k.Sorter(a.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyed := Record(a.Name, k.Keys(), s)
+		if a.Name != "counting" && a.Name != "radix" && !reflect.DeepEqual(ints.Events, keyed.Events) {
+			t.Errorf("%s: events differ", a.Name)
+		}
+		if !slices.Equal(keyed.Result(), ints.Result()) || !slices.Equal(k.S, ints.Result()) {
+			t.Errorf("%s: got %v and %v", a.Name, keyed.Result(), k.S)
+		}
+	}
+}
+
+// TestKeyed checks that the visualized array follows the sorted structs,
+// also when the sorter is run again.
+func TestKeyed(t *testing.T) {
+	type file struct {
+		name string
+		size int
+	}
+	files := []file{{"c", 3}, {"a", 9}, {"d", 1}, {"b", 9}, {"e", 5}}
+	k := Keyed[file]{
+		S:   files,
+		Cmp: func(a, b file) int { return strings.Compare(a.name, b.name) },
+		Key: func(f file) int { return f.size },
+	}
+	for _, name := range Names() {
+		if _, err := k.Sorter(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := k.Sorter("missing"); err == nil {
+		t.Error("Expected an error for an unknown algorithm")
+	}
+
+	s, _ := k.Sorter("merge")
+	arr := k.Keys()
+	tr := Record("merge", arr, s)
+	if err := tr.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{9, 9, 3, 1, 5}; !slices.Equal(arr, want) || !slices.Equal(tr.Result(), want) {
+		t.Errorf("Expected bars %v, got %v and %v", want, arr, tr.Result())
+	}
+	if files[0].name != "a" || files[4].name != "e" {
+		t.Errorf("Expected the files to be sorted by name, got %v", files)
+	}
+	tr = Record("merge", []int{1, 9, 5, 3, 9}, s)
+	if !slices.Equal(tr.Result(), []int{9, 9, 3, 1, 5}) || files[0].name != "a" || files[3].name != "d" {
+		t.Errorf("Expected a second run to sort the files again, got %v and %v", tr.Result(), files)
+	}
+
+	// each run starts from the elements in the order of its array
+	ints := Keyed[int]{S: []int{3, 1, 2}, Key: func(v int) int { return v }}
+	s, _ = ints.Sorter("insertion")
+	for run := 0; run < 2; run++ {
+		b := []int{3, 1, 2}
+		s(b, nil)
+		if !slices.Equal(b, []int{1, 2, 3}) || !slices.Equal(ints.S, b) {
+			t.Errorf("Run %d: expected both sorted, got %v and %v", run, b, ints.S)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package gsv
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Tagged is a value with the index it had before sorting
+type Tagged struct {
+	Value int
+	Pos   int
+}
+
+// Tag pairs each value of arr with its index
+func Tag(arr []int) []Tagged {
+	s := make([]Tagged, len(arr))
+	for i, v := range arr {
+		s[i] = Tagged{v, i}
+	}
+	return s
+}
+
+// tagValue is the key of a Tagged
+func tagValue(r Tagged) int {
+	return r.Value
+}
+
+// inOrder reports whether the records of equal value in s are ordered
+// by their original index
+func inOrder(s []Tagged) bool {
+	for i := 1; i < len(s); i++ {
+		if s[i].Value == s[i-1].Value && s[i].Pos < s[i-1].Pos {
+			return false
+		}
+	}
+	return true
+}
+
+// Stability is the result of CheckStability
+type Stability struct {
+	Name string
+	// Claimed is the Stable property the algorithm is registered with
+	Claimed bool
+	// Stable is set if equal values kept their order in all inputs
+	Stable bool
+	// Input is the first input whose equal values were reordered
+	Input []int
+}
+
+// CheckStability sorts each of inputs as Tagged records with the generic
+// version of the algorithm registered as name and reports whether equal
+// values kept their order. Inputs without repeated values cannot tell.
+func CheckStability(name string, inputs ...[]int) (Stability, error) {
+	a, ok := Lookup(name)
+	if !ok {
+		return Stability{}, fmt.Errorf("gsv: unknown algorithm %q", name)
+	}
+	st := Stability{Name: name, Claimed: a.Stable, Stable: true}
+	for _, arr := range inputs {
+		if err := a.Check(arr); err != nil {
+			return st, err
+		}
+		k := Keyed[Tagged]{S: Tag(arr), Key: tagValue}
+		s, err := k.Sorter(name)
+		if err != nil {
+			return st, err
+		}
+		s(k.Keys(), nil)
+		if !inOrder(k.S) {
+			st.Stable = false
+			st.Input = arr
+			break
+		}
+	}
+	return st, nil
+}
+
+// WriteStability writes a table of results to w. Algorithms that behave
+// differently from their registration are flagged.
+func WriteStability(w io.Writer, results []Stability) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "algorithm\tregistered\tobserved\t")
+	for _, st := range results {
+		note := ""
+		if st.Claimed != st.Stable {
+			note = "mismatch"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", st.Name, stableName(st.Claimed), stableName(st.Stable), note)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("gsv: %w", err)
+	}
+	return nil
+}
+
+func stableName(stable bool) string {
+	if stable {
+		return "stable"
+	}
+	return "unstable"
+}
+
+// OriginSorter returns a TraceSorter running the algorithm registered as
+// name on the values tagged with their index. Ahead of every operation
+// reported to t, origin is updated to hold the original index of each
+// bar, for GifOptions.Origin. origin must be as long as the sorted array;
+// it starts out in the order before sorting.
+func OriginSorter(name string, origin []int) (TraceSorter, error) {
+	if _, ok := keyedSort[Tagged](name); !ok {
+		return nil, fmt.Errorf("gsv: no generic version of %q", name)
+	}
+	reset := func() {
+		for i := range origin {
+			origin[i] = i
+		}
+	}
+	reset()
+	return func(arr []int, t Tracer) {
+		k := Keyed[Tagged]{S: Tag(arr), Key: tagValue}
+		reset()
+		track := TraceFunc(func(e Event) {
+			switch e.Op {
+			case OpSwap:
+				origin[e.I], origin[e.J] = k.S[e.I].Pos, k.S[e.J].Pos
+			case OpWrite:
+				origin[e.I] = k.S[e.I].Pos
+			}
+		})
+		s, _ := k.Sorter(name)
+		s(arr, MultiTracer(track, tracer(t)))
+	}, nil
+}
@@ -0,0 +1,109 @@
+package gsv
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestCheckStability checks that the algorithms behave as registered on
+// inputs with many equal values.
+func TestCheckStability(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var inputs [][]int
+	for i := 0; i < 20; i++ {
+		inputs = append(inputs, gen.FewUnique(32, 9, rng))
+	}
+	for _, a := range Algorithms() {
+		if a.Rand != nil || a.Name == "sleep" {
+			continue
+		}
+		st, err := CheckStability(a.Name, inputs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.Stable != a.Stable {
+			t.Errorf("%s: registered as stable=%v, observed %v on %v", a.Name, a.Stable, st.Stable, st.Input)
+		}
+		if !st.Stable && st.Input == nil {
+			t.Errorf("%s: expected the reordered input", a.Name)
+		}
+	}
+
+	if _, err := CheckStability("missing", inputs[0]); err == nil {
+		t.Error("Expected an error for an unknown algorithm")
+	}
+	if _, err := CheckStability("bogo", inputs[0]); err == nil {
+		t.Error("Expected an error for an input the algorithm cannot sort")
+	}
+}
+
+// TestWriteStability checks that deviations from the registry are flagged.
+func TestWriteStability(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteStability(&buf, []Stability{
+		{Name: "merge", Claimed: true, Stable: true},
+		{Name: "quick", Claimed: true, Stable: false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || strings.Contains(lines[1], "mismatch") || !strings.Contains(lines[2], "mismatch") {
+		t.Errorf("Unexpected table:\n%s", buf.String())
+	}
+}
+
+// TestOriginSorter checks that origin follows the bars and that the GIF
+// colours them by it.
+func TestOriginSorter(t *testing.T) {
+	arr := []int{2, 1, 2, 1, 3}
+	origin := make([]int, len(arr))
+	s, err := OriginSorter("selection", origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frames [][]int
+	s(arr, TraceFunc(func(e Event) {
+		if e.Mutates() {
+			frames = append(frames, slices.Clone(origin))
+		}
+	}))
+	if !slices.Equal(arr, []int{1, 1, 2, 2, 3}) || !slices.Equal(origin, []int{1, 3, 2, 0, 4}) {
+		t.Errorf("Got %v with origin %v", arr, origin)
+	}
+	if len(frames) == 0 || !slices.Equal(frames[0], []int{1, 0, 2, 3, 4}) {
+		t.Errorf("Expected origin to be updated ahead of each operation, got %v", frames)
+	}
+	if _, err := OriginSorter("missing", origin); err == nil {
+		t.Error("Expected an error for an unknown algorithm")
+	}
+
+	var buf bytes.Buffer
+	gv := NewGifWriter(&buf, Config{Max: 3, Fps: 10, Mode: ModeBars})
+	gv.Origin = make([]int, len(arr))
+	s, _ = OriginSorter("merge", gv.Origin)
+	if err := Run(gv, "merge", []int{2, 1, 2, 1, 3}, s); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := g.Config.ColorModel.(color.Palette); !ok || len(p) < len(originPalette) {
+		t.Fatalf("Expected the origin colours in the palette, got %v", g.Config.ColorModel)
+	}
+	first := g.Image[0]
+	if c, want := first.ColorIndexAt(0, 2), originColor(0, 5); c != want {
+		t.Errorf("Expected colour %d for the first bar, got %d", want, c)
+	}
+	if c, want := first.ColorIndexAt(4, 2), originColor(4, 5); c != want || want != uint8(gifTransparent+originColors) {
+		t.Errorf("Expected colour %d for the last bar, got %d", want, c)
+	}
+}
@@ -0,0 +1,142 @@
+package gsv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Counter is a DepthTracer that counts the operations of a run
+type Counter struct {
+	Comparisons int `json:"comparisons"`
+	Swaps       int `json:"swaps"`
+	Writes      int `json:"writes"`
+	AuxWrites   int `json:"auxWrites"`
+	// AuxMemory is the number of elements of auxiliary memory used,
+	// taken from the highest index written by AuxWrite
+	AuxMemory int `json:"auxMemory"`
+	// MaxDepth is the deepest nesting of recursive calls; 0 for
+	// algorithms without recursion
+	MaxDepth int `json:"maxDepth"`
+	depth    int
+}
+
+// Compare counts a comparison
+func (c *Counter) Compare(i, j int) {
+	c.Comparisons++
+}
+
+// Swap counts an exchange of two elements
+func (c *Counter) Swap(i, j int) {
+	c.Swaps++
+}
+
+// Write counts a write to the array
+func (c *Counter) Write(i, v int) {
+	c.Writes++
+}
+
+// AuxWrite counts a write to an auxiliary buffer and its size
+func (c *Counter) AuxWrite(i, v int) {
+	c.AuxWrites++
+	if i >= c.AuxMemory {
+		c.AuxMemory = i + 1
+	}
+}
+
+// Mark ignores the roles of indices
+func (c *Counter) Mark(i int, kind MarkKind) {}
+
+// Enter counts a recursive call
+func (c *Counter) Enter() {
+	c.depth++
+	if c.depth > c.MaxDepth {
+		c.MaxDepth = c.depth
+	}
+}
+
+// Leave counts the return of a recursive call
+func (c *Counter) Leave() {
+	c.depth--
+}
+
+// Frames returns the number of frames the run shows: the initial state
+// and one after every change of the array
+func (c *Counter) Frames() int {
+	return 1 + c.Swaps + c.Writes
+}
+
+// Stats are the operation counts of an algorithm on an input
+type Stats struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	Counter
+	Frames int `json:"frames"`
+}
+
+// Measure sorts a copy of arr with s and counts its operations
+func Measure(name string, arr []int, s TraceSorter) Stats {
+	c := &Counter{}
+	s(append([]int(nil), arr...), c)
+	return Stats{Name: name, N: len(arr), Counter: *c, Frames: c.Frames()}
+}
+
+// statsColumns are the column names of a statistics table
+var statsColumns = []string{"algorithm", "n", "comparisons", "swaps", "writes", "auxWrites", "auxMemory", "maxDepth", "frames"}
+
+// row returns the values of s in the order of statsColumns
+func (s Stats) row() []string {
+	row := []string{s.Name}
+	for _, v := range []int{s.N, s.Comparisons, s.Swaps, s.Writes, s.AuxWrites, s.AuxMemory, s.MaxDepth, s.Frames} {
+		row = append(row, strconv.Itoa(v))
+	}
+	return row
+}
+
+// StatsFormats lists the formats WriteStats supports
+var StatsFormats = []string{"text", "csv", "json"}
+
+// WriteStats writes stats to w as an aligned "text" table, as "csv" with
+// a header line or as a "json" array
+func WriteStats(w io.Writer, format string, stats []Stats) error {
+	var err error
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		for _, row := range append([][]string{statsColumns}, statsRows(stats)...) {
+			fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+		}
+		err = tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(statsColumns)
+		cw.WriteAll(statsRows(stats))
+		err = cw.Error()
+	case "json":
+		if stats == nil {
+			stats = []Stats{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(stats)
+	default:
+		return fmt.Errorf("gsv: unknown statistics format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("gsv: %w", err)
+	}
+	return nil
+}
+
+// statsRows returns the table rows of stats
+func statsRows(stats []Stats) [][]string {
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		rows[i] = s.row()
+	}
+	return rows
+}
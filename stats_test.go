@@ -0,0 +1,91 @@
+package gsv
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCounter checks the counts of runs whose operations are known.
+func TestCounter(t *testing.T) {
+	s := Measure("bubble", []int{3, 2, 1}, BubbleSortTraced)
+	if s.N != 3 || s.Comparisons != 6 || s.Swaps != 3 || s.Writes != 0 || s.MaxDepth != 0 || s.Frames != 4 {
+		t.Errorf("Unexpected bubble sort counts %+v", s)
+	}
+
+	s = Measure("merge", []int{4, 3, 2, 1, 0, 5, 6, 7}, MergeSortTraced)
+	if s.AuxMemory != 8 || s.Writes != s.AuxWrites || s.MaxDepth != 4 {
+		t.Errorf("Unexpected merge sort counts %+v", s)
+	}
+
+	arr := []int{5, 1, 4}
+	s = Measure("quick", arr, QuickSortTraced)
+	if !reflect.DeepEqual(arr, []int{5, 1, 4}) {
+		t.Error("Measure changed its input")
+	}
+	if s.MaxDepth < 2 {
+		t.Errorf("Expected quick sort to recurse, got %+v", s)
+	}
+
+	c := &Counter{}
+	tr := NewTrace("stooge", []int{3, 1, 2})
+	StoogeSortTraced([]int{3, 1, 2}, MultiTracer(tr.Tracer(), c))
+	if c.MaxDepth != 2 || c.Comparisons != 4 {
+		t.Errorf("Expected MultiTracer to pass on the recursion, got %+v", c)
+	}
+	if len(tr.Events) != c.Comparisons+c.Swaps {
+		t.Errorf("Expected %d events, got %d", c.Comparisons+c.Swaps, len(tr.Events))
+	}
+}
+
+// TestWriteStats checks the three report formats.
+func TestWriteStats(t *testing.T) {
+	stats := []Stats{
+		Measure("insertion", []int{2, 1}, InsertionSortTraced),
+		Measure("merge", []int{2, 1}, MergeSortTraced),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStats(&buf, "text", stats); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "comparisons") || !strings.Contains(lines[2], "merge") {
+		t.Errorf("Unexpected text table:\n%s", buf.String())
+	}
+	if len(lines[0]) != len(lines[1]) {
+		t.Errorf("Expected aligned columns:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteStats(&buf, "csv", stats); err != nil {
+		t.Fatal(err)
+	}
+	expected := "algorithm,n,comparisons,swaps,writes,auxWrites,auxMemory,maxDepth,frames\n" +
+		"insertion,2,1,0,2,0,0,0,3\n" +
+		"merge,2,1,0,2,2,2,2,3\n"
+	if buf.String() != expected {
+		t.Errorf("Expected CSV\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteStats(&buf, "json", stats); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Stats
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, stats) {
+		t.Errorf("Expected %+v, got %+v", stats, decoded)
+	}
+	if !strings.Contains(buf.String(), `"comparisons": 1`) {
+		t.Errorf("Expected flat JSON fields, got %s", buf.String())
+	}
+
+	if err := WriteStats(&buf, "xml", stats); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
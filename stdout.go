@@ -0,0 +1,508 @@
+package gsv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// ClearMode selects how a StdoutVisualizer removes the previous frame
+type ClearMode int
+
+const (
+	// ClearScreen clears the whole terminal before every frame
+	ClearScreen ClearMode = iota
+	// ClearHome moves the cursor to the top left corner and draws over
+	// the previous frame
+	ClearHome
+	// ClearNone prints every frame below the previous one, e.g. for logs
+	ClearNone
+	// ClearDiff draws on the alternate screen with a hidden cursor and
+	// rewrites only the columns that changed since the previous frame.
+	// Complete or an interrupt restores the terminal; Complete then prints
+	// the final frame on the normal screen.
+	ClearDiff
+)
+
+// Escape sequences used by ClearDiff
+const (
+	enterScreen = "\033[?1049h\033[?25l\033[H\033[2J"
+	leaveScreen = "\033[?25h\033[?1049l"
+)
+
+var errInterrupted = errors.New("gsv: interrupted")
+
+// StdoutVisualizer is a visualizer that draws frames as text, by default
+// on the terminal
+type StdoutVisualizer struct {
+	// Fps overrides the frame rate of the Config if set
+	Fps int
+	// Clear selects how the previous frame is removed
+	Clear ClearMode
+	// Out receives the frames; os.Stdout is used if nil
+	Out io.Writer
+	// Color selects the colours of the bars. Colour frames also highlight
+	// the operations reported by AddEvent.
+	Color ColorMode
+	// Theme colours the bars by value; Rainbow is used if nil
+	Theme Theme
+
+	cfg   *Config
+	style *textStyle
+	hl    highlight
+	next  time.Time
+	buf   bytes.Buffer
+	prev  *textFrame
+	err   error
+
+	// mu guards the output against the interrupt handler of ClearDiff
+	mu     sync.Mutex
+	active bool
+	stop   chan struct{}
+}
+
+// NewStdoutVisualizer returns a terminal visualizer that renders with cfg.
+// A zero StdoutVisualizer uses DefaultConfig at Setup time.
+func NewStdoutVisualizer(cfg Config) *StdoutVisualizer {
+	return &StdoutVisualizer{cfg: &cfg}
+}
+
+// Setup prepares the output for a new run
+func (sv *StdoutVisualizer) Setup(name string) error {
+	if sv.cfg == nil {
+		cfg := DefaultConfig()
+		sv.cfg = &cfg
+	}
+	sv.style = nil
+	mode := sv.Color
+	if mode == ColorAuto {
+		mode = ColorNone
+		if sv.Out == nil || sv.Out == os.Stdout {
+			mode = DetectColor()
+		}
+	}
+	if mode != ColorNone {
+		sv.style = &textStyle{mode: mode, theme: sv.Theme, hl: &sv.hl}
+	}
+	sv.hl.reset()
+	sv.next = time.Time{}
+	sv.prev = nil
+	sv.err = nil
+	if sv.Clear == ClearDiff && !sv.cfg.Quiet {
+		sv.write([]byte(enterScreen))
+		sv.active = true
+		sv.catchInterrupt()
+	}
+	return sv.err
+}
+
+// AddEvent records an operation to be highlighted in the next frame
+func (sv *StdoutVisualizer) AddEvent(e Event) {
+	sv.hl.add(e)
+}
+
+// AddFrame draws arr once the time for the next frame has come
+func (sv *StdoutVisualizer) AddFrame(arr []int) error {
+	sv.mu.Lock()
+	err := sv.err
+	sv.mu.Unlock()
+	if err != nil || sv.cfg.Quiet {
+		return err
+	}
+	f := drawText(*sv.cfg, arr, sv.style)
+	sv.hl.next()
+	return sv.show(f)
+}
+
+// show writes f once the time for the next frame has come
+func (sv *StdoutVisualizer) show(f *textFrame) error {
+	sv.buf.Reset()
+	switch sv.Clear {
+	case ClearScreen:
+		sv.buf.WriteString("\033[H\033[2J")
+	case ClearHome:
+		sv.buf.WriteString("\033[H")
+	}
+	if sv.Clear == ClearDiff {
+		f.writeDiff(&sv.buf, sv.prev)
+		sv.prev = f
+	} else {
+		f.writeTo(&sv.buf)
+	}
+	if sv.Clear == ClearNone {
+		sv.buf.WriteByte('\n')
+	}
+
+	sv.wait()
+	sv.write(sv.buf.Bytes())
+	return sv.err
+}
+
+// Complete finishes the run. With ClearDiff it restores the terminal and
+// prints the final frame.
+func (sv *StdoutVisualizer) Complete() error {
+	if sv.stop != nil {
+		close(sv.stop)
+		sv.stop = nil
+	}
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	if sv.active {
+		sv.active = false
+		sv.writeLocked([]byte(leaveScreen))
+		if sv.prev != nil {
+			sv.buf.Reset()
+			sv.prev.writeTo(&sv.buf)
+			sv.writeLocked(sv.buf.Bytes())
+		}
+	}
+	return sv.err
+}
+
+// catchInterrupt restores the terminal if the process is interrupted
+// during a ClearDiff run and then delivers the signal again, so that the
+// program ends as it would have without the handler
+func (sv *StdoutVisualizer) catchInterrupt() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	sv.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		select {
+		case sig := <-sigs:
+			signal.Stop(sigs)
+			sv.mu.Lock()
+			if sv.active {
+				sv.active = false
+				sv.writeLocked([]byte(leaveScreen))
+			}
+			if sv.err == nil {
+				sv.err = errInterrupted
+			}
+			sv.mu.Unlock()
+			raise(sig)
+		case <-stop:
+			signal.Stop(sigs)
+		}
+	}(sv.stop)
+}
+
+// raise sends sig to the own process, which exits if nothing else handles
+// the signal
+func raise(sig os.Signal) {
+	if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+		return
+	}
+	os.Exit(130)
+}
+
+// write writes p to the output unless an error occurred before
+func (sv *StdoutVisualizer) write(p []byte) {
+	sv.mu.Lock()
+	sv.writeLocked(p)
+	sv.mu.Unlock()
+}
+
+func (sv *StdoutVisualizer) writeLocked(p []byte) {
+	if sv.err != nil {
+		return
+	}
+	if _, err := sv.out().Write(p); err != nil {
+		sv.err = fmt.Errorf("gsv: %w", err)
+	}
+}
+
+// wait sleeps until the next frame is due
+func (sv *StdoutVisualizer) wait() {
+	fps := sv.Fps
+	if fps <= 0 {
+		fps = sv.cfg.Fps
+	}
+	if fps <= 0 {
+		return
+	}
+	now := time.Now()
+	if sv.next.After(now) {
+		time.Sleep(sv.next.Sub(now))
+		now = sv.next
+	}
+	sv.next = now.Add(time.Second / time.Duration(fps))
+}
+
+func (sv *StdoutVisualizer) out() io.Writer {
+	if sv.Out == nil {
+		return os.Stdout
+	}
+	return sv.Out
+}
+
+// textFrame is a frame as a grid of characters, stored column by column.
+// Colour frames also hold the colour of each cell.
+type textFrame struct {
+	cols   int
+	rows   int
+	cells  []rune
+	colors []color.RGBA
+	mode   ColorMode
+}
+
+// textStyle selects the colours of a frame
+type textStyle struct {
+	mode  ColorMode
+	theme Theme
+	hl    *highlight
+}
+
+// newTextFrame returns an empty frame. With a colour style the frame has
+// room for the colours of the cells.
+func newTextFrame(cols, rows int, st *textStyle) *textFrame {
+	if rows < 0 {
+		rows = 0
+	}
+	f := &textFrame{cols: cols, rows: rows, cells: make([]rune, cols*rows)}
+	for k := range f.cells {
+		f.cells[k] = ' '
+	}
+	if st != nil && st.mode > ColorNone {
+		f.mode = st.mode
+		f.colors = make([]color.RGBA, cols*rows)
+	}
+	return f
+}
+
+// drawText renders arr like buildImage does. ModeDots and ModeBars use
+// one column per element and one row per value step; without a style the
+// bars are drawn with '#'. With a style they are drawn with blocks
+// coloured by value, except for the pivot and compared or swapped bars,
+// which take their GIF colours.
+func drawText(cfg Config, arr []int, st *textStyle) *textFrame {
+	switch cfg.Mode {
+	case ModeBlocks:
+		return drawBlocks(cfg, arr, st)
+	case ModeBraille:
+		return drawBraille(cfg, arr, st)
+	}
+	f := newTextFrame(len(arr), cfg.Max, st)
+	fill := '#'
+	if f.colors != nil {
+		fill = '█'
+	}
+	for x, v := range arr {
+		if f.colors != nil {
+			f.setColor(x, st.color(x, v, cfg.Max))
+		}
+		if v < 1 {
+			continue
+		}
+		if v > cfg.Max {
+			v = cfg.Max
+		}
+		bottom := cfg.Max - 1
+		if cfg.Mode != ModeBars {
+			bottom = cfg.Max - v
+		}
+		for y := cfg.Max - v; y <= bottom; y++ {
+			f.cells[x*f.rows+y] = fill
+		}
+	}
+	return f
+}
+
+// eighths holds the block characters filling 1 to 8 eighths of a cell
+var eighths = []rune(" ▁▂▃▄▅▆▇█")
+
+// drawBlocks draws ModeBlocks: one column per element with eight value
+// steps per row
+func drawBlocks(cfg Config, arr []int, st *textStyle) *textFrame {
+	f := newTextFrame(len(arr), (cfg.Max+7)/8, st)
+	for x, v := range arr {
+		if f.colors != nil {
+			f.setColor(x, st.color(x, v, cfg.Max))
+		}
+		v = clampValue(v, cfg.Max)
+		col := f.column(x)
+		for y := len(col) - 1; v > 0; y-- {
+			n := v
+			if n > 8 {
+				n = 8
+			}
+			col[y] = eighths[n]
+			v -= n
+		}
+	}
+	return f
+}
+
+// brailleDots holds the bits of the Braille dots of the left and the right
+// column of a cell, from top to bottom
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// drawBraille draws ModeBraille: two elements per column with four value
+// steps per row. Both elements of a column share the colour of the
+// highlighted or else the higher one.
+func drawBraille(cfg Config, arr []int, st *textStyle) *textFrame {
+	f := newTextFrame((len(arr)+1)/2, (cfg.Max+3)/4, st)
+	for x, v := range arr {
+		v = clampValue(v, cfg.Max)
+		col := f.column(x / 2)
+		for y := range col {
+			for d := 0; d < 4; d++ {
+				if (len(col)-1-y)*4+3-d < v {
+					if col[y] == ' ' {
+						col[y] = 0x2800
+					}
+					col[y] |= brailleDots[x%2][d]
+				}
+			}
+		}
+	}
+	for c := 0; f.colors != nil && c < f.cols; c++ {
+		x := 2 * c
+		if x+1 < len(arr) && (st.hl.role(x+1) > st.hl.role(x) ||
+			st.hl.role(x+1) == st.hl.role(x) && arr[x+1] > arr[x]) {
+			x++
+		}
+		f.setColor(c, st.color(x, arr[x], cfg.Max))
+	}
+	return f
+}
+
+// clampValue limits v to the range drawn for max
+func clampValue(v, max int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// color returns the colour of the bar of value v at index x
+func (st *textStyle) color(x, v, max int) color.RGBA {
+	switch r := st.hl.role(x); r {
+	case rolePivot, roleCompare, roleSwap:
+		return gifPalette[r+1].(color.RGBA)
+	}
+	theme := st.theme
+	if theme == nil {
+		theme = Rainbow
+	}
+	if max <= 1 {
+		return theme(1)
+	}
+	return theme(float64(v-1) / float64(max-1))
+}
+
+// column returns the cells of column x from top to bottom
+func (f *textFrame) column(x int) []rune {
+	return f.cells[x*f.rows : (x+1)*f.rows]
+}
+
+// setColor sets the colour of all cells of column x
+func (f *textFrame) setColor(x int, c color.RGBA) {
+	for k := x * f.rows; k < (x+1)*f.rows; k++ {
+		f.colors[k] = c
+	}
+}
+
+// differs reports whether cell k differs from the same cell of prev.
+// Blank cells differ only in their character.
+func (f *textFrame) differs(prev *textFrame, k int) bool {
+	if f.cells[k] != prev.cells[k] {
+		return true
+	}
+	return f.colors != nil && f.cells[k] != ' ' && f.colors[k] != prev.colors[k]
+}
+
+// changed reports whether column x differs from the same column of prev
+func (f *textFrame) changed(prev *textFrame, x int) bool {
+	for k := x * f.rows; k < (x+1)*f.rows; k++ {
+		if f.differs(prev, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTo writes the frame row by row
+func (f *textFrame) writeTo(buf *bytes.Buffer) {
+	for y := 0; y < f.rows; y++ {
+		f.writeRow(buf, y)
+		buf.WriteByte('\n')
+	}
+}
+
+func (f *textFrame) writeRow(buf *bytes.Buffer, y int) {
+	current := -1
+	for x := 0; x < f.cols; x++ {
+		k := x*f.rows + y
+		if f.colors != nil && f.cells[k] != ' ' && (current < 0 || f.colors[current] != f.colors[k]) {
+			writeColor(buf, f.mode, f.colors[k])
+			current = k
+		}
+		buf.WriteRune(f.cells[k])
+	}
+	if current >= 0 {
+		buf.WriteString(colorReset)
+	}
+}
+
+// writeDiff writes the cells of the columns that differ from prev, using
+// cursor positioning. Everything is redrawn from the top left corner if
+// prev has another size or most columns changed. No newline follows the
+// last row, so a frame as high as the terminal does not scroll.
+func (f *textFrame) writeDiff(buf *bytes.Buffer, prev *textFrame) {
+	if prev == nil || prev.cols != f.cols || prev.rows != f.rows || prev.mode != f.mode {
+		buf.WriteString("\033[H\033[2J")
+		f.writeAll(buf)
+		return
+	}
+	var changed []int
+	for x := 0; x < f.cols; x++ {
+		if f.changed(prev, x) {
+			changed = append(changed, x)
+		}
+	}
+	if len(changed) > f.cols/2 {
+		buf.WriteString("\033[H")
+		f.writeAll(buf)
+		return
+	}
+	current := -1
+	for _, x := range changed {
+		for k := x * f.rows; k < (x+1)*f.rows; k++ {
+			if !f.differs(prev, k) {
+				continue
+			}
+			fmt.Fprintf(buf, "\033[%d;%dH", k-x*f.rows+1, x+1)
+			if f.colors != nil && f.cells[k] != ' ' && (current < 0 || f.colors[current] != f.colors[k]) {
+				writeColor(buf, f.mode, f.colors[k])
+				current = k
+			}
+			buf.WriteRune(f.cells[k])
+		}
+	}
+	if current >= 0 {
+		buf.WriteString(colorReset)
+	}
+}
+
+// writeAll writes the rows separated by line breaks that also work on a
+// terminal in raw mode
+func (f *textFrame) writeAll(buf *bytes.Buffer) {
+	for y := 0; y < f.rows; y++ {
+		if y > 0 {
+			buf.WriteString("\r\n")
+		}
+		f.writeRow(buf, y)
+	}
+}
@@ -0,0 +1,170 @@
+package gsv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStdoutFrames checks the frames drawn in the dot and bar modes.
+func TestStdoutFrames(t *testing.T) {
+	for _, tc := range []struct {
+		mode int
+		want string
+	}{
+		{1, "  #\n # \n#  \n"},
+		{2, "  #\n ##\n###\n"},
+	} {
+		var buf bytes.Buffer
+		sv := NewStdoutVisualizer(Config{Max: 3, Fps: 1000, Mode: tc.mode})
+		sv.Clear = ClearNone
+		sv.Out = &buf
+		if err := Run(sv, "test", []int{3, 1, 2}, BubbleSortTraced); err != nil {
+			t.Fatal(err)
+		}
+		frames := strings.Split(strings.TrimSuffix(buf.String(), "\n\n"), "\n\n")
+		if len(frames) != 3 {
+			t.Fatalf("mode %d: got %d frames, want 3", tc.mode, len(frames))
+		}
+		if got := frames[len(frames)-1] + "\n"; got != tc.want {
+			t.Errorf("mode %d: final frame\n%s\nwant\n%s", tc.mode, got, tc.want)
+		}
+	}
+}
+
+// TestWriteStdoutLegacy checks that Config.WriteStdout draws the first
+// two modes as it did before StdoutVisualizer.
+func TestWriteStdoutLegacy(t *testing.T) {
+	for mode, want := range map[int]string{
+		ModeDots: "   \n # \n ##\n",
+		ModeBars: "###\n###\n# #\n",
+	} {
+		var buf bytes.Buffer
+		writeLegacyText(&buf, Config{Max: 3, Mode: mode}, []int{3, 1, 2})
+		if buf.String() != want {
+			t.Errorf("mode %d: got\n%s\nwant\n%s", mode, buf.String(), want)
+		}
+	}
+}
+
+// TestStdoutClear checks the escape codes written ahead of a frame.
+func TestStdoutClear(t *testing.T) {
+	for clear, prefix := range map[ClearMode]string{
+		ClearScreen: "\033[H\033[2J",
+		ClearHome:   "\033[H",
+	} {
+		var buf bytes.Buffer
+		sv := &StdoutVisualizer{Clear: clear, Out: &buf, Fps: 1000}
+		sv.Setup("test")
+		sv.AddFrame([]int{1})
+		if !strings.HasPrefix(buf.String(), prefix) || strings.Count(buf.String(), "\033") != strings.Count(prefix, "\033") {
+			t.Errorf("clear %d: got %q", clear, buf.String())
+		}
+	}
+}
+
+// TestStdoutPacing checks that frames are shown at the frame rate.
+func TestStdoutPacing(t *testing.T) {
+	sv := NewStdoutVisualizer(Config{Max: 2, Fps: 1})
+	sv.Fps = 50
+	sv.Out = &bytes.Buffer{}
+	sv.Setup("test")
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		sv.AddFrame([]int{1, 2})
+	}
+	if d := time.Since(start); d < 100*time.Millisecond || d > time.Second {
+		t.Errorf("6 frames at 50 fps took %v", d)
+	}
+}
+
+// TestStdoutErrors checks that write errors stop the run.
+func TestStdoutErrors(t *testing.T) {
+	sv := NewStdoutVisualizer(Config{Max: 2, Fps: 1000})
+	sv.Out = &failingWriter{}
+	err := Run(sv, "test", []int{2, 1, 0}, BubbleSortTraced)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Expected the write error, got %v", err)
+	}
+}
+
+// TestStdoutDiff checks that only the changed cells are redrawn.
+func TestStdoutDiff(t *testing.T) {
+	var buf bytes.Buffer
+	sv := NewStdoutVisualizer(Config{Max: 3, Fps: 1000, Mode: 2})
+	sv.Clear = ClearDiff
+	sv.Out = &buf
+	if err := sv.Setup("test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != enterScreen {
+		t.Errorf("Setup wrote %q, want %q", got, enterScreen)
+	}
+
+	buf.Reset()
+	sv.AddFrame([]int{1, 2, 3, 3})
+	if want := "\033[H\033[2J  ##\r\n ###\r\n####"; buf.String() != want {
+		t.Errorf("first frame %q, want %q", buf.String(), want)
+	}
+
+	// swapping the first two bars changes one cell in each column
+	buf.Reset()
+	sv.AddFrame([]int{2, 1, 3, 3})
+	if want := "\033[2;1H#\033[2;2H "; buf.String() != want {
+		t.Errorf("diff %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	sv.AddFrame([]int{2, 1, 3, 3})
+	if buf.Len() != 0 {
+		t.Errorf("unchanged frame wrote %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := sv.Complete(); err != nil {
+		t.Fatal(err)
+	}
+	if want := leaveScreen + "  ##\n# ##\n####\n"; buf.String() != want {
+		t.Errorf("Complete wrote %q, want %q", buf.String(), want)
+	}
+}
+
+// TestStdoutBlocks checks the eighth blocks mode in the terminal and in GIFs.
+func TestStdoutBlocks(t *testing.T) {
+	f := drawText(Config{Max: 16, Mode: ModeBlocks}, []int{0, 3, 8, 12, 16, 20}, nil)
+	var buf bytes.Buffer
+	f.writeTo(&buf)
+	if want := "   ▄██\n ▃████\n"; buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	for _, mode := range []int{ModeBlocks, ModeBraille} {
+		arr := []int{0, 3, 8, 12, 16}
+		got := buildImage(Config{Max: 16, Mode: mode}, GifOptions{}, arr, nil)
+		want := buildImage(Config{Max: 16, Mode: ModeBars}, GifOptions{}, arr, nil)
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("GIF frame of mode %d differs from ModeBars", mode)
+		}
+	}
+}
+
+// TestStdoutBraille checks the size and the patterns of the Braille mode.
+func TestStdoutBraille(t *testing.T) {
+	// an 80x24 terminal shows 160 elements with 96 heights
+	arr := make([]int, 160)
+	for k := range arr {
+		arr[k] = k * 96 / 159
+	}
+	f := drawText(Config{Max: 96, Mode: ModeBraille}, arr, nil)
+	if f.cols != 80 || f.rows != 24 {
+		t.Errorf("got %dx%d cells, want 80x24", f.cols, f.rows)
+	}
+
+	f = drawText(Config{Max: 8, Mode: ModeBraille}, []int{1, 4, 8, 6, 0}, nil)
+	var buf bytes.Buffer
+	f.writeTo(&buf)
+	if want := " ⣧ \n⣸⣿ \n"; buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
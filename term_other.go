@@ -0,0 +1,22 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package gsv
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoTerminal = errors.New("gsv: raw terminal mode is not supported on this platform")
+
+func makeRaw(fd int) (func() error, error) {
+	return nil, errNoTerminal
+}
+
+func interruptible(fd int) (*os.File, func(), error) {
+	return nil, nil, errNoTerminal
+}
+
+func termSize(fd int) (cols, rows int, err error) {
+	return 0, 0, errNoTerminal
+}
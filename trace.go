@@ -0,0 +1,311 @@
+package gsv
+
+import "fmt"
+
+// Op identifies the kind of operation reported to a Tracer
+type Op uint8
+
+const (
+	// OpCompare reports that the elements at I and J were compared
+	OpCompare Op = iota + 1
+	// OpSwap reports that the elements at I and J were exchanged
+	OpSwap
+	// OpWrite reports that the element at I was set to J
+	OpWrite
+	// OpAuxWrite reports that index I of an auxiliary buffer was set to J
+	OpAuxWrite
+	// OpMark reports that index I was marked with the MarkKind J
+	OpMark
+)
+
+var opNames = []string{
+	OpCompare:  "compare",
+	OpSwap:     "swap",
+	OpWrite:    "write",
+	OpAuxWrite: "auxwrite",
+	OpMark:     "mark",
+}
+
+func (op Op) String() string {
+	if int(op) < len(opNames) && opNames[op] != "" {
+		return opNames[op]
+	}
+	return "unknown"
+}
+
+// MarshalText encodes op by name
+func (op Op) MarshalText() ([]byte, error) {
+	if op.String() == "unknown" {
+		return nil, fmt.Errorf("gsv: unknown operation %d", op)
+	}
+	return []byte(op.String()), nil
+}
+
+// UnmarshalText decodes an operation name written by MarshalText
+func (op *Op) UnmarshalText(text []byte) error {
+	for k, name := range opNames {
+		if name != "" && name == string(text) {
+			*op = Op(k)
+			return nil
+		}
+	}
+	return fmt.Errorf("gsv: unknown operation %q", text)
+}
+
+// MarkKind describes the role of an index reported through Tracer.Mark
+type MarkKind int
+
+const (
+	// MarkPivot marks the element the algorithm currently partitions around
+	MarkPivot MarkKind = iota + 1
+	// MarkSorted marks an element that has reached its final position
+	MarkSorted
+)
+
+// Event is a single operation performed by a sorting algorithm.
+// Index arguments refer to positions in the array being sorted; a
+// negative index stands for a value the algorithm holds outside of it.
+type Event struct {
+	Op Op  `json:"op"`
+	I  int `json:"i"`
+	J  int `json:"j"`
+}
+
+// Mutates reports whether e changes the array being sorted
+func (e Event) Mutates() bool {
+	return e.Op == OpSwap || e.Op == OpWrite
+}
+
+// Apply performs e on arr. Events that do not change the array are ignored.
+func (e Event) Apply(arr []int) {
+	switch e.Op {
+	case OpSwap:
+		arr[e.I], arr[e.J] = arr[e.J], arr[e.I]
+	case OpWrite:
+		arr[e.I] = e.J
+	}
+}
+
+// Report hands e to the matching method of t
+func (e Event) Report(t Tracer) {
+	switch e.Op {
+	case OpCompare:
+		t.Compare(e.I, e.J)
+	case OpSwap:
+		t.Swap(e.I, e.J)
+	case OpWrite:
+		t.Write(e.I, e.J)
+	case OpAuxWrite:
+		t.AuxWrite(e.I, e.J)
+	case OpMark:
+		t.Mark(e.I, MarkKind(e.J))
+	}
+}
+
+// Tracer receives the individual operations of a sorting algorithm.
+// Algorithms perform each change on the array before reporting it, so
+// a Tracer holding the array always sees the state after the operation.
+type Tracer interface {
+	Compare(i, j int)
+	Swap(i, j int)
+	Write(i, v int)
+	AuxWrite(i, v int)
+	Mark(i int, kind MarkKind)
+}
+
+// DepthTracer is a Tracer that also follows the recursion of an
+// algorithm. Recursive algorithms call Enter at the start of every call
+// and Leave when it returns.
+type DepthTracer interface {
+	Tracer
+	Enter()
+	Leave()
+}
+
+// enter reports the start of a recursive call if t follows the recursion
+func enter(t Tracer) {
+	if dt, ok := t.(DepthTracer); ok {
+		dt.Enter()
+	}
+}
+
+// leave reports the end of a recursive call if t follows the recursion
+func leave(t Tracer) {
+	if dt, ok := t.(DepthTracer); ok {
+		dt.Leave()
+	}
+}
+
+// TraceFunc is a Tracer that hands every operation to a function as an Event
+type TraceFunc func(Event)
+
+// Compare reports a comparison of the elements at i and j
+func (tf TraceFunc) Compare(i, j int) {
+	tf(Event{OpCompare, i, j})
+}
+
+// Swap reports an exchange of the elements at i and j
+func (tf TraceFunc) Swap(i, j int) {
+	tf(Event{OpSwap, i, j})
+}
+
+// Write reports that the element at i was set to v
+func (tf TraceFunc) Write(i, v int) {
+	tf(Event{OpWrite, i, v})
+}
+
+// AuxWrite reports that index i of an auxiliary buffer was set to v
+func (tf TraceFunc) AuxWrite(i, v int) {
+	tf(Event{OpAuxWrite, i, v})
+}
+
+// Mark reports that index i plays the role kind
+func (tf TraceFunc) Mark(i int, kind MarkKind) {
+	tf(Event{OpMark, i, int(kind)})
+}
+
+// MultiTracer returns a Tracer that reports every operation to all of ts.
+// Recursive calls are passed on to those of ts that are DepthTracers.
+func MultiTracer(ts ...Tracer) Tracer {
+	return multiTracer{
+		TraceFunc: func(e Event) {
+			for _, t := range ts {
+				e.Report(t)
+			}
+		},
+		ts: ts,
+	}
+}
+
+// multiTracer is the DepthTracer returned by MultiTracer
+type multiTracer struct {
+	TraceFunc
+	ts []Tracer
+}
+
+func (mt multiTracer) Enter() {
+	for _, t := range mt.ts {
+		enter(t)
+	}
+}
+
+func (mt multiTracer) Leave() {
+	for _, t := range mt.ts {
+		leave(t)
+	}
+}
+
+// TraceSorter defines a function type for sorting algorithms that report
+// each operation to a Tracer
+type TraceSorter func([]int, Tracer)
+
+// Sorter adapts ts to the frame based Sorter type
+func (ts TraceSorter) Sorter() Sorter {
+	return func(arr []int, frameGen FrameGen) {
+		ts(arr, FrameTracer(arr, frameGen))
+	}
+}
+
+// FrameTracer returns a Tracer that passes arr to frameGen once for its
+// initial state and again after every operation that changes it.
+// A nil frameGen yields a Tracer that discards all operations.
+func FrameTracer(arr []int, frameGen FrameGen) Tracer {
+	if frameGen == nil {
+		return nopTracer{}
+	}
+	frameGen(arr)
+	return TraceFunc(func(e Event) {
+		if e.Mutates() {
+			frameGen(arr)
+		}
+	})
+}
+
+// VisualizerTracer returns a Tracer that adds a frame of arr to v for its
+// initial state and after every operation that changes it. If v is an
+// EventVisualizer it also receives every operation ahead of its frame.
+// Once v fails no more frames are added; use Run to get the error.
+func VisualizerTracer(arr []int, v Visualizer) Tracer {
+	vt := newVisTracer(arr, v)
+	return TraceFunc(vt.trace)
+}
+
+// Run sorts arr with s and renders every step with v under the given
+// name. The sort is stopped as soon as v fails and the first error of v
+// is returned.
+func Run(v Visualizer, name string, arr []int, s TraceSorter) (err error) {
+	if err := v.Setup(name); err != nil {
+		v.Complete()
+		return err
+	}
+	vt := newVisTracer(arr, v)
+	vt.abort = true
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(abortRun); !ok {
+				panic(r)
+			}
+		}
+		if cerr := v.Complete(); vt.err == nil {
+			vt.err = cerr
+		}
+		err = vt.err
+	}()
+	if vt.err == nil {
+		s(arr, TraceFunc(vt.trace))
+	}
+	return nil
+}
+
+// abortRun is raised by a visTracer to stop a sort once its visualizer
+// failed
+type abortRun struct{}
+
+// visTracer feeds the operations on arr to a Visualizer
+type visTracer struct {
+	arr   []int
+	v     Visualizer
+	ev    EventVisualizer
+	err   error
+	abort bool
+}
+
+// newVisTracer adds the initial frame of arr to v
+func newVisTracer(arr []int, v Visualizer) *visTracer {
+	vt := &visTracer{arr: arr, v: v}
+	vt.ev, _ = v.(EventVisualizer)
+	vt.err = v.AddFrame(arr)
+	return vt
+}
+
+func (vt *visTracer) trace(e Event) {
+	if vt.err != nil {
+		if vt.abort {
+			panic(abortRun{})
+		}
+		return
+	}
+	if vt.ev != nil {
+		vt.ev.AddEvent(e)
+	}
+	if e.Mutates() {
+		vt.err = vt.v.AddFrame(vt.arr)
+	}
+}
+
+// nopTracer discards all operations
+type nopTracer struct{}
+
+func (nopTracer) Compare(i, j int)          {}
+func (nopTracer) Swap(i, j int)             {}
+func (nopTracer) Write(i, v int)            {}
+func (nopTracer) AuxWrite(i, v int)         {}
+func (nopTracer) Mark(i int, kind MarkKind) {}
+
+// tracer replaces a nil Tracer with one that discards all operations
+func tracer(t Tracer) Tracer {
+	if t == nil {
+		return nopTracer{}
+	}
+	return t
+}
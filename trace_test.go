@@ -0,0 +1,43 @@
+package gsv
+
+import (
+	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestTraceEvents checks that replaying the reported events on a copy of
+// the input reproduces the array the algorithm produced.
+func TestTraceEvents(t *testing.T) {
+	for k, v := range tracedMap {
+		arr := gen.Random(8, 9, nil)
+		replay := cloneArray(arr)
+		compares := 0
+		v(arr, TraceFunc(func(e Event) {
+			e.Apply(replay)
+			if e.Op == OpCompare {
+				compares++
+			}
+		}))
+		for i := range arr {
+			if arr[i] != replay[i] {
+				t.Errorf("%s: replayed events give %v, sorted array is %v", k, replay, arr)
+				break
+			}
+		}
+		if a, _ := Lookup(k); compares == 0 && a.Comparison {
+			t.Errorf("%s: expected compare events", k)
+		}
+	}
+}
+
+// TestFrameTracer checks that the FrameGen adapter emits the initial state
+// and one frame per change.
+func TestFrameTracer(t *testing.T) {
+	arr := []int{2, 1, 3}
+	frames := 0
+	BubbleSort(arr, func(_ []int) { frames++ })
+	if frames != 2 {
+		t.Errorf("Expected 2 frames, got %d", frames)
+	}
+}
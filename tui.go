@@ -0,0 +1,381 @@
+package gsv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TUI is an interactive terminal player. Users choose an algorithm and an
+// input from menus and can then pause, step through the frames in both
+// directions, change the speed and restart.
+type TUI struct {
+	// Algorithms are offered in the menu by name
+	Algorithms map[string]TraceSorter
+	// Inputs generate the arrays to sort by name; gen.Generators is used
+	// if nil
+	Inputs map[string]gen.Generator
+	// Config sets the frame rate and the mode. A Count or Max of 0 fits
+	// the values to the terminal. Every run draws its input from
+	// Config.Seed or, if it is 0, from a new seed shown in the status line.
+	Config Config
+	// Color selects the colours of the bars as for StdoutVisualizer
+	Color ColorMode
+	// Theme colours the bars by value; Rainbow is used if nil
+	Theme Theme
+}
+
+// tuiHelp lists the keys of the player
+const tuiHelp = "space pause  ←/→ step  ↑/↓ speed  r restart  m menu  q quit"
+
+// Run shows the TUI on the terminal of in and out until the user quits
+func (ui *TUI) Run(in, out *os.File) error {
+	restore, err := makeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("gsv: %w", err)
+	}
+	defer restore()
+	tty, release, err := interruptible(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("gsv: %w", err)
+	}
+	defer release()
+	done := make(chan struct{})
+	defer close(done)
+	cols, rows, err := termSize(int(out.Fd()))
+	if err != nil || cols < 1 || rows < 2 {
+		cols, rows = 80, 24
+	}
+	mode := ui.Color
+	if mode == ColorAuto {
+		mode = DetectColor()
+	}
+	return ui.run(readKeys(tty, done), out, cols, rows, mode)
+}
+
+// tuiSession is the state of a running TUI
+type tuiSession struct {
+	ui         *TUI
+	keys       <-chan string
+	out        io.Writer
+	cols, rows int
+	mode       ColorMode
+	buf        bytes.Buffer
+	err        error
+}
+
+// run shows the menus and plays the chosen runs until the user quits or
+// keys is closed
+func (ui *TUI) run(keys <-chan string, out io.Writer, cols, rows int, mode ColorMode) error {
+	s := &tuiSession{ui: ui, keys: keys, out: out, cols: cols, rows: rows, mode: mode}
+	algos := sortedNames(ui.Algorithms)
+	if ui.Inputs == nil {
+		ui.Inputs = gen.Generators
+	}
+	inputs := sortedNames(ui.Inputs)
+	algo, input := 0, 0
+
+	s.write(enterScreen)
+	for ok := true; ok; {
+		if algo, ok = s.menu("Choose an algorithm", algos, algo); !ok {
+			break
+		}
+		if input, ok = s.menu("Choose the input", inputs, input); !ok {
+			break
+		}
+		ok = s.play(algos[algo], inputs[input])
+	}
+	s.write(leaveScreen)
+	return s.err
+}
+
+// menu lets the user choose one of items, starting at item k. It reports
+// false if the user quits.
+func (s *tuiSession) menu(title string, items []string, k int) (int, bool) {
+	if len(items) == 0 {
+		return 0, false
+	}
+	for {
+		s.buf.Reset()
+		s.buf.WriteString("\033[H\033[2J" + title + ":\r\n\r\n")
+		visible := s.rows - 4
+		if visible < 1 {
+			visible = 1
+		}
+		first := k - visible/2
+		if first > len(items)-visible {
+			first = len(items) - visible
+		}
+		if first < 0 {
+			first = 0
+		}
+		for i := first; i < len(items) && i < first+visible; i++ {
+			marker := "  "
+			if i == k {
+				marker = "> "
+			}
+			s.buf.WriteString(marker + items[i] + "\r\n")
+		}
+		s.buf.WriteString("\r\n↑/↓ select  enter choose  q quit")
+		s.flush()
+		if s.err != nil {
+			return k, false
+		}
+
+		key, ok := <-s.keys
+		switch {
+		case !ok || key == "q" || key == "ctrl-c":
+			return k, false
+		case key == "enter" || key == " ":
+			return k, true
+		case key == "up" || key == "k":
+			k = (k + len(items) - 1) % len(items)
+		case key == "down" || key == "j":
+			k = (k + 1) % len(items)
+		}
+	}
+}
+
+// play records algo on an input and plays it back. It reports whether the
+// user asked for the menu rather than to quit.
+func (s *tuiSession) play(algo, input string) bool {
+	cfg := s.fit()
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	arr := fitValues(s.ui.Inputs[input](cfg.Count, cfg.Max, rng), cfg.Max)
+	if a, ok := Lookup(algo); ok {
+		if err := a.Check(arr); err != nil {
+			return s.notice(err.Error())
+		}
+	}
+	s.buf.Reset()
+	s.buf.WriteString("\033[H\033[2Jrecording " + algo + " …")
+	s.flush()
+	p := NewPlayer(Record(algo, arr, s.ui.Algorithms[algo]))
+
+	var style *textStyle
+	if s.mode > ColorNone {
+		style = &textStyle{mode: s.mode, theme: s.ui.Theme, hl: &p.hl}
+	}
+	fps := cfg.Fps
+	tick := time.NewTicker(time.Second / time.Duration(fps))
+	defer tick.Stop()
+	paused := false
+	var prev *textFrame
+	for {
+		f := drawText(cfg, p.Array(), style)
+		s.buf.Reset()
+		f.writeDiff(&s.buf, prev)
+		prev = f
+		state := ""
+		switch {
+		case p.Frame() == p.Frames()-1:
+			state = "  done"
+		case paused:
+			state = "  paused"
+		}
+		status := fmt.Sprintf("%s on %s  seed %d  frame %d/%d  %d fps%s   %s", algo, input, seed, p.Frame(), p.Frames()-1, fps, state, tuiHelp)
+		if r := []rune(status); len(r) > s.cols {
+			status = string(r[:s.cols])
+		}
+		fmt.Fprintf(&s.buf, "\033[%d;1H\033[K%s", f.rows+1, status)
+		s.flush()
+		if s.err != nil {
+			return false
+		}
+
+		var next <-chan time.Time
+		if !paused {
+			next = tick.C
+		}
+		select {
+		case key, ok := <-s.keys:
+			switch {
+			case !ok || key == "q" || key == "ctrl-c":
+				return false
+			case key == "m" || key == "esc":
+				return true
+			case key == " ":
+				paused = !paused
+				if p.Frame() == p.Frames()-1 {
+					p.Seek(0)
+					paused = false
+				}
+			case key == "right" || key == "l":
+				paused = true
+				p.Step(1)
+			case key == "left" || key == "h":
+				paused = true
+				p.Step(-1)
+			case key == "up" || key == "+":
+				if fps < 1000 {
+					fps *= 2
+					tick.Reset(time.Second / time.Duration(fps))
+				}
+			case key == "down" || key == "-":
+				if fps > 1 {
+					fps /= 2
+					tick.Reset(time.Second / time.Duration(fps))
+				}
+			case key == "r":
+				p.Seek(0)
+				paused = false
+			}
+		case <-next:
+			p.Step(1)
+			if p.Frame() == p.Frames()-1 {
+				paused = true
+			}
+		}
+	}
+}
+
+// notice shows text in the status line until a key is pressed. It
+// reports whether the user asked for the menu rather than to quit.
+func (s *tuiSession) notice(text string) bool {
+	status := text + "   any key menu  q quit"
+	if r := []rune(status); len(r) > s.cols {
+		status = string(r[:s.cols])
+	}
+	s.buf.Reset()
+	fmt.Fprintf(&s.buf, "\033[H\033[2J\033[%d;1H%s", s.rows, status)
+	s.flush()
+	if s.err != nil {
+		return false
+	}
+	key, ok := <-s.keys
+	return ok && key != "q" && key != "ctrl-c"
+}
+
+// fit returns the Config of a run, with Count and Max fitted to the
+// terminal if they are 0. One row is left for the status line.
+func (s *tuiSession) fit() Config {
+	cfg := s.ui.Config
+	if cfg.Mode == 0 {
+		cfg.Mode = ModeBars
+	}
+	if cfg.Fps <= 0 {
+		cfg.Fps = 30
+	}
+	steps, perColumn := 1, 1
+	switch cfg.Mode {
+	case ModeBlocks:
+		steps = 8
+	case ModeBraille:
+		steps, perColumn = 4, 2
+	}
+	if cfg.Count <= 0 {
+		cfg.Count = s.cols * perColumn
+	}
+	if cfg.Max <= 0 {
+		cfg.Max = (s.rows - 1) * steps
+	}
+	if cfg.Max < 1 {
+		cfg.Max = 1
+	}
+	return cfg
+}
+
+// fitValues scales arr down if it holds values above max, e.g. those of
+// gen.Permutation, so that all of them fit on the terminal
+func fitValues(arr []int, max int) []int {
+	highest := 0
+	for _, v := range arr {
+		if v > highest {
+			highest = v
+		}
+	}
+	if highest <= max {
+		return arr
+	}
+	for i, v := range arr {
+		arr[i] = (v*max + highest - 1) / highest
+	}
+	return arr
+}
+
+// flush writes the buffer to the terminal
+func (s *tuiSession) flush() {
+	s.write(s.buf.String())
+}
+
+func (s *tuiSession) write(text string) {
+	if s.err != nil {
+		return
+	}
+	if _, err := io.WriteString(s.out, text); err != nil {
+		s.err = fmt.Errorf("gsv: %w", err)
+	}
+}
+
+// sortedNames returns the keys of m in alphabetical order
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readKeys reads key presses from r until reading fails or done is
+// closed. Closing done does not interrupt a pending read.
+func readKeys(r io.Reader, done <-chan struct{}) <-chan string {
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Read(buf)
+			for _, key := range parseKeys(buf[:n]) {
+				select {
+				case keys <- key:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return keys
+}
+
+// arrowKeys maps the final byte of the arrow key sequences to key names
+var arrowKeys = map[byte]string{'A': "up", 'B': "down", 'C': "right", 'D': "left"}
+
+// parseKeys splits input read in raw mode into key names: "up", "down",
+// "left", "right", "enter", "esc", "ctrl-c" or the typed character
+func parseKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		switch {
+		case len(b) >= 3 && b[0] == 0x1b && (b[1] == '[' || b[1] == 'O'):
+			if name, ok := arrowKeys[b[2]]; ok {
+				keys = append(keys, name)
+			}
+			b = b[3:]
+			continue
+		case b[0] == 0x1b:
+			keys = append(keys, "esc")
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, "enter")
+		case b[0] == 3:
+			keys = append(keys, "ctrl-c")
+		default:
+			keys = append(keys, string(rune(b[0])))
+		}
+		b = b[1:]
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+package gsv
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"simonwaldherr.de/go/GolangSortingVisualization/gen"
+)
+
+// TestParseKeys checks the key names read from a raw terminal.
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("\x1b[A\x1b[Dq \r\x1bOC\x03\x1b"))
+	expected := []string{"up", "left", "q", " ", "enter", "right", "ctrl-c", "esc"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestTUI drives the menus and the player with scripted keys.
+func TestTUI(t *testing.T) {
+	ui := &TUI{
+		Algorithms: map[string]TraceSorter{"bubble": BubbleSortTraced, "quick": QuickSortTraced},
+		Inputs:     map[string]gen.Generator{"random": gen.Random},
+		Config:     Config{Fps: 1000, Seed: 5},
+	}
+	keys := make(chan string)
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- ui.run(keys, &out, 30, 6, ColorNone)
+	}()
+	for _, key := range []string{"down", "enter", "enter", "right", "left", "r", "m", "q"} {
+		keys <- key
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	text := out.String()
+	for _, want := range []string{enterScreen, "> quick", "recording quick", "quick on random  seed 5 ", leaveScreen} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected the output to contain %q", want)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(text, "\r\n", ""), "\n") {
+		t.Error("Expected rows separated by \\r\\n in raw mode")
+	}
+}
+
+// TestTUICheck checks that an input the algorithm cannot sort is reported
+// instead of played.
+func TestTUICheck(t *testing.T) {
+	ui := &TUI{
+		Algorithms: map[string]TraceSorter{"radix": RadixSortTraced},
+		Inputs: map[string]gen.Generator{"negative": func(n, max int, rng *rand.Rand) []int {
+			return []int{3, -1, 2}
+		}},
+		Config: Config{Fps: 1000, Seed: 5},
+	}
+	keys := make(chan string)
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- ui.run(keys, &out, 80, 6, ColorNone)
+	}()
+	for _, key := range []string{"enter", "enter", "x", "q"} {
+		keys <- key
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if text := out.String(); !strings.Contains(text, "cannot sort the negative value -1") || strings.Contains(text, "recording") {
+		t.Errorf("Expected the error instead of the run, got %q", text)
+	}
+}
+
+// TestReadKeys checks that the key reader stops once done is closed.
+func TestReadKeys(t *testing.T) {
+	r, w := io.Pipe()
+	done := make(chan struct{})
+	keys := readKeys(r, done)
+	go w.Write([]byte("ab"))
+	if key := <-keys; key != "a" {
+		t.Fatalf("Expected a, got %q", key)
+	}
+	close(done)
+	w.Close()
+	for range keys {
+	}
+}